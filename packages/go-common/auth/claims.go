@@ -39,6 +39,10 @@ type Claims struct {
 	Role     Role         `json:"role"`
 	Type     TokenType    `json:"type"`
 	Provider AuthProvider `json:"provider,omitempty"`
+	// Scopes carries RFC 6749 scope strings (e.g. "girok:read",
+	// "admin:users") so personal-service can authorize requests without a
+	// round trip back to auth-service.
+	Scopes []string `json:"scopes,omitempty"`
 }
 
 // Valid roles for validation
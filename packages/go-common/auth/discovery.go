@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler serves GET /.well-known/jwks.json: every public key in m's
+// KeySet still valid for verification, so a downstream service can verify
+// tokens this manager issues without sharing its HS256 secret.
+func JWKSHandler(m *JWTManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, m.KeySet().JWKS())
+	}
+}
+
+// DiscoveryDocument is the subset of OIDC discovery metadata this package
+// can answer without knowing the host application's full route layout; the
+// caller fills in issuer and whichever endpoints it exposes (see
+// DiscoveryHandler).
+type DiscoveryDocument struct {
+	Issuer                           string   `json:"issuer"`
+	JWKSURI                          string   `json:"jwks_uri"`
+	TokenEndpoint                    string   `json:"token_endpoint,omitempty"`
+	AuthorizationEndpoint            string   `json:"authorization_endpoint,omitempty"`
+	ResponseTypesSupported           []string `json:"response_types_supported"`
+	SubjectTypesSupported            []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// DiscoveryHandler serves GET /.well-known/openid-configuration, describing
+// how to verify tokens m issues. tokenEndpoint/authorizationEndpoint may be
+// left empty when the host application doesn't expose the full
+// authorization-code flow.
+func DiscoveryHandler(m *JWTManager, issuer, tokenEndpoint, authorizationEndpoint string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, DiscoveryDocument{
+			Issuer:                           issuer,
+			JWKSURI:                          issuer + "/.well-known/jwks.json",
+			TokenEndpoint:                    tokenEndpoint,
+			AuthorizationEndpoint:            authorizationEndpoint,
+			ResponseTypesSupported:           []string{"id_token", "token"},
+			SubjectTypesSupported:            []string{"public"},
+			IDTokenSigningAlgValuesSupported: []string{m.config.Algorithm},
+		})
+	}
+}
+
+// RotateSigningKeyHandler serves an admin-only endpoint that rotates m's
+// active signing key (see JWTManager.RotateSigningKey); mount it behind
+// RoleMiddleware(RoleMaster) in the host application's router.
+func RotateSigningKeyHandler(m *JWTManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		kid, err := m.RotateSigningKey()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"kid": kid})
+	}
+}
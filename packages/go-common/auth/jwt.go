@@ -1,7 +1,17 @@
 package auth
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -14,9 +24,21 @@ var (
 
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	Secret              string
-	AccessTokenExpiry   time.Duration
-	RefreshTokenExpiry  time.Duration
+	Secret             string
+	AccessTokenExpiry  time.Duration
+	RefreshTokenExpiry time.Duration
+	// Algorithm selects the signing method: "HS256" (default), "RS256", or
+	// "ES256". RS256/ES256 require PrivateKey and KeyID; Secret is then only
+	// kept around to keep verifying tokens minted before a migration to one
+	// of them.
+	Algorithm string
+	// PrivateKey is a PEM-encoded RSA (PKCS#1/PKCS#8) or EC private key,
+	// required when Algorithm is RS256/ES256.
+	PrivateKey string
+	// KeyID tags every token this manager signs with RS256/ES256, so a
+	// downstream service holding only the public half (via KeySet, e.g.
+	// behind GET /.well-known/jwks.json) knows which key verifies it.
+	KeyID string
 }
 
 // DefaultConfig returns default JWT configuration
@@ -25,17 +47,41 @@ func DefaultConfig(secret string) *JWTConfig {
 		Secret:             secret,
 		AccessTokenExpiry:  15 * time.Minute,
 		RefreshTokenExpiry: 7 * 24 * time.Hour,
+		Algorithm:          "HS256",
 	}
 }
 
 // JWTManager handles JWT operations
 type JWTManager struct {
 	config *JWTConfig
+	keySet *KeySet
+
+	mu         sync.RWMutex
+	signingKey crypto.PrivateKey // nil for HS256
 }
 
-// NewJWTManager creates a new JWT manager
-func NewJWTManager(config *JWTConfig) *JWTManager {
-	return &JWTManager{config: config}
+// NewJWTManager creates a new JWT manager. For RS256/ES256, config.PrivateKey
+// is parsed eagerly and its public half registered under config.KeyID in the
+// manager's KeySet (see JWKS); a malformed key fails fast here rather than
+// on the first token a caller tries to sign.
+func NewJWTManager(config *JWTConfig) (*JWTManager, error) {
+	m := &JWTManager{config: config, keySet: NewKeySet()}
+
+	if !isAsymmetric(config.Algorithm) {
+		return m, nil
+	}
+
+	priv, pub, err := parsePrivateKey(config.Algorithm, config.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("auth: parsing %s private key: %w", config.Algorithm, err)
+	}
+	m.signingKey = priv
+	m.keySet.Add(config.KeyID, pub, config.Algorithm)
+	return m, nil
+}
+
+func isAsymmetric(algorithm string) bool {
+	return algorithm == "RS256" || algorithm == "ES256"
 }
 
 // GenerateAccessToken generates an access token
@@ -63,13 +109,40 @@ func (m *JWTManager) generateToken(userID, email, username string, role Role, pr
 		Provider: provider,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(m.config.Secret))
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	switch m.config.Algorithm {
+	case "RS256":
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = m.config.KeyID
+		return token.SignedString(m.signingKey)
+	case "ES256":
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		token.Header["kid"] = m.config.KeyID
+		return token.SignedString(m.signingKey)
+	default:
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(m.config.Secret))
+	}
 }
 
-// ValidateToken validates a JWT token and returns claims
+// ValidateToken validates a JWT token and returns claims. A token carrying a
+// kid header is verified against m.keySet (RS256/ES256, possibly signed by a
+// key already rotated out but not yet expired); one without falls back to
+// the HS256 secret.
 func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		if kid, _ := token.Header["kid"].(string); kid != "" {
+			pub, algorithm, err := m.keySet.Lookup(kid)
+			if err != nil {
+				return nil, err
+			}
+			if !signingMethodMatches(token.Method, algorithm) {
+				return nil, ErrInvalidToken
+			}
+			return pub, nil
+		}
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, ErrInvalidToken
 		}
@@ -91,6 +164,116 @@ func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
+func signingMethodMatches(method jwt.SigningMethod, algorithm string) bool {
+	switch algorithm {
+	case "RS256":
+		_, ok := method.(*jwt.SigningMethodRSA)
+		return ok
+	case "ES256":
+		_, ok := method.(*jwt.SigningMethodECDSA)
+		return ok
+	default:
+		return false
+	}
+}
+
+// RotateSigningKey generates a fresh RS256/ES256 key pair, starts signing
+// new tokens with it, and marks the previous key verify-only in m.keySet:
+// it stays in the published JWKS (see KeySet.JWKS) for RefreshTokenExpiry
+// longer, so refresh tokens already signed with it keep validating until
+// they'd have expired anyway, the same rationale as oidc.KeyManager.Rotate
+// in auth-service.
+func (m *JWTManager) RotateSigningKey() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !isAsymmetric(m.config.Algorithm) {
+		return "", errors.New("key rotation requires an asymmetric algorithm")
+	}
+
+	kid, err := randomKeyID()
+	if err != nil {
+		return "", err
+	}
+	priv, pub, err := generateKeyPair(m.config.Algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	previousKid := m.config.KeyID
+	m.signingKey = priv
+	m.config.KeyID = kid
+	m.keySet.Add(kid, pub, m.config.Algorithm)
+	if previousKid != "" {
+		m.keySet.ExpireAfter(previousKid, m.config.RefreshTokenExpiry)
+	}
+	return kid, nil
+}
+
+// KeySet returns the manager's public-key set, for mounting JWKSHandler and
+// DiscoveryHandler.
+func (m *JWTManager) KeySet() *KeySet {
+	return m.keySet
+}
+
+func parsePrivateKey(algorithm, pemKey string) (crypto.PrivateKey, crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, nil, errors.New("invalid PEM-encoded private key")
+	}
+
+	switch algorithm {
+	case "RS256":
+		if priv, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+			return priv, &priv.PublicKey, nil
+		}
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		priv, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, nil, errors.New("not an RSA private key")
+		}
+		return priv, &priv.PublicKey, nil
+	case "ES256":
+		priv, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return priv, &priv.PublicKey, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+}
+
+func generateKeyPair(algorithm string) (crypto.PrivateKey, crypto.PublicKey, error) {
+	switch algorithm {
+	case "RS256":
+		priv, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, nil, err
+		}
+		return priv, &priv.PublicKey, nil
+	case "ES256":
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return priv, &priv.PublicKey, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+}
+
+func randomKeyID() (string, error) {
+	b := make([]byte, 9)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
 // TokenPair represents access and refresh token pair
 type TokenPair struct {
 	AccessToken  string `json:"accessToken"`
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// ErrUnknownKey is returned by KeySet.Lookup for a kid that was never
+// registered, or was and has since aged out past ExpireAfter.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// keyEntry is one public verification key tracked by a KeySet.
+type keyEntry struct {
+	publicKey crypto.PublicKey
+	algorithm string
+	// expiresAt is set by ExpireAfter when a key is retired from signing;
+	// nil means it's still eligible to sign new tokens.
+	expiresAt *time.Time
+}
+
+// KeySet holds every RS256/ES256 public key currently valid for verifying a
+// token's signature, keyed by kid. Rotating in a new signing key retires
+// (rather than removes) the previous one, so tokens it already signed keep
+// validating until ExpireAfter's TTL elapses.
+type KeySet struct {
+	mu   sync.RWMutex
+	keys map[string]keyEntry
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]keyEntry)}
+}
+
+// Add registers kid as an active verification key.
+func (k *KeySet) Add(kid string, pub crypto.PublicKey, algorithm string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[kid] = keyEntry{publicKey: pub, algorithm: algorithm}
+}
+
+// ExpireAfter marks kid verify-only: it drops out of Lookup and JWKS once
+// ttl passes, long enough for any refresh token already signed with it to
+// still redeem.
+func (k *KeySet) ExpireAfter(kid string, ttl time.Duration) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	entry, ok := k.keys[kid]
+	if !ok {
+		return
+	}
+	expiresAt := time.Now().Add(ttl)
+	entry.expiresAt = &expiresAt
+	k.keys[kid] = entry
+}
+
+// Lookup resolves kid's public key and the algorithm it was registered
+// under, refusing a key that has aged out past ExpireAfter.
+func (k *KeySet) Lookup(kid string) (crypto.PublicKey, string, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	entry, ok := k.keys[kid]
+	if !ok {
+		return nil, "", ErrUnknownKey
+	}
+	if entry.expiresAt != nil && entry.expiresAt.Before(time.Now()) {
+		return nil, "", ErrUnknownKey
+	}
+	return entry.publicKey, entry.algorithm, nil
+}
+
+// JWK is the public-key representation published at /.well-known/jwks.json.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS renders every key still valid for verification (signing or
+// verify-only but not yet expired), public halves only.
+func (k *KeySet) JWKS() JWKSet {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	now := time.Now()
+	set := JWKSet{Keys: make([]JWK, 0, len(k.keys))}
+	for kid, entry := range k.keys {
+		if entry.expiresAt != nil && entry.expiresAt.Before(now) {
+			continue
+		}
+
+		jwk := JWK{Use: "sig", Alg: entry.algorithm, Kid: kid}
+		switch pub := entry.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			jwk.Kty = "EC"
+			jwk.Crv = pub.Curve.Params().Name
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub.X.Bytes())
+			jwk.Y = base64.RawURLEncoding.EncodeToString(pub.Y.Bytes())
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
@@ -0,0 +1,222 @@
+// Command bolt-user-storage is a reference implementation of the
+// userstorage.Store contract, backed by a local BoltDB file instead of
+// Postgres. It exists to prove the plugin boundary auth-service exposes via
+// USER_STORAGE_PLUGIN_CMD — swap it for DynamoDB, LDAP, or an internal
+// directory by shipping a different binary that serves the same contract.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/plugin/userstorage"
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-plugin"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketUsers      = []byte("users")       // id -> json(Record)
+	bucketEmail      = []byte("by_email")    // email -> id
+	bucketUsername   = []byte("by_username") // username -> id
+	bucketExternalID = []byte("by_external") // external id -> id
+	bucketProviderID = []byte("by_provider") // provider|provider_id -> id
+)
+
+type boltStore struct {
+	db *bolt.DB
+}
+
+func newBoltStore(path string) (*boltStore, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{bucketUsers, bucketEmail, bucketUsername, bucketExternalID, bucketProviderID} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltStore{db: db}, nil
+}
+
+func providerKey(provider, providerID string) []byte {
+	return []byte(provider + "|" + providerID)
+}
+
+func (s *boltStore) Create(rec *userstorage.Record) error {
+	if rec.ID == "" {
+		rec.ID = uuid.New().String()
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketEmail).Get([]byte(rec.Email)) != nil {
+			return errors.New("user already exists")
+		}
+		if err := tx.Bucket(bucketUsers).Put([]byte(rec.ID), data); err != nil {
+			return err
+		}
+		if err := tx.Bucket(bucketEmail).Put([]byte(rec.Email), []byte(rec.ID)); err != nil {
+			return err
+		}
+		if rec.Username != "" {
+			if err := tx.Bucket(bucketUsername).Put([]byte(rec.Username), []byte(rec.ID)); err != nil {
+				return err
+			}
+		}
+		if rec.ExternalID != "" {
+			if err := tx.Bucket(bucketExternalID).Put([]byte(rec.ExternalID), []byte(rec.ID)); err != nil {
+				return err
+			}
+		}
+		if rec.ProviderID != "" {
+			if err := tx.Bucket(bucketProviderID).Put(providerKey(rec.Provider, rec.ProviderID), []byte(rec.ID)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *boltStore) FindByID(id string) (*userstorage.Record, error) {
+	return s.findByIDBytes([]byte(id))
+}
+
+func (s *boltStore) findByIDBytes(id []byte) (*userstorage.Record, error) {
+	var rec userstorage.Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketUsers).Get(id)
+		if data == nil {
+			return userstorage.ErrNotFound
+		}
+		return json.Unmarshal(data, &rec)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (s *boltStore) findByIndex(bucket []byte, key string) (*userstorage.Record, error) {
+	var id []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucket).Get([]byte(key))
+		if v == nil {
+			return userstorage.ErrNotFound
+		}
+		id = append([]byte{}, v...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.findByIDBytes(id)
+}
+
+func (s *boltStore) FindByEmail(email string) (*userstorage.Record, error) {
+	return s.findByIndex(bucketEmail, email)
+}
+
+func (s *boltStore) FindByUsername(username string) (*userstorage.Record, error) {
+	return s.findByIndex(bucketUsername, username)
+}
+
+func (s *boltStore) FindByExternalID(externalID string) (*userstorage.Record, error) {
+	return s.findByIndex(bucketExternalID, externalID)
+}
+
+func (s *boltStore) FindByProviderID(provider, providerID string) (*userstorage.Record, error) {
+	return s.findByIndex(bucketProviderID, string(providerKey(provider, providerID)))
+}
+
+func (s *boltStore) Update(rec *userstorage.Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketUsers).Get([]byte(rec.ID)) == nil {
+			return userstorage.ErrNotFound
+		}
+		return tx.Bucket(bucketUsers).Put([]byte(rec.ID), data)
+	})
+}
+
+func (s *boltStore) Delete(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketUsers).Get([]byte(id))
+		if data == nil {
+			return userstorage.ErrNotFound
+		}
+		var rec userstorage.Record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		tx.Bucket(bucketEmail).Delete([]byte(rec.Email))
+		tx.Bucket(bucketUsername).Delete([]byte(rec.Username))
+		tx.Bucket(bucketExternalID).Delete([]byte(rec.ExternalID))
+		if rec.ProviderID != "" {
+			tx.Bucket(bucketProviderID).Delete(providerKey(rec.Provider, rec.ProviderID))
+		}
+		return tx.Bucket(bucketUsers).Delete([]byte(id))
+	})
+}
+
+func (s *boltStore) List(page, perPage int) ([]userstorage.Record, int64, error) {
+	var all []userstorage.Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketUsers).ForEach(func(_, data []byte) error {
+			var rec userstorage.Record
+			if err := json.Unmarshal(data, &rec); err != nil {
+				return err
+			}
+			all = append(all, rec)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := int64(len(all))
+	start := (page - 1) * perPage
+	if start < 0 || start >= len(all) {
+		return []userstorage.Record{}, total, nil
+	}
+	end := start + perPage
+	if end > len(all) {
+		end = len(all)
+	}
+	return all[start:end], total, nil
+}
+
+func main() {
+	dbPath := os.Getenv("BOLT_USER_STORAGE_PATH")
+	if dbPath == "" {
+		dbPath = "user-storage.db"
+	}
+
+	store, err := newBoltStore(dbPath)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: userstorage.Handshake,
+		Plugins: map[string]plugin.Plugin{
+			"user_storage": &userstorage.GRPCPlugin{Impl: store},
+		},
+		GRPCServer: plugin.DefaultGRPCServer,
+	})
+}
@@ -0,0 +1,63 @@
+// Command keyctl lets an operator rotate or revoke the RS256 signing key
+// used for access and ID tokens out-of-band from the running server, e.g.
+// after a suspected compromise.
+//
+// Usage:
+//
+//	keyctl rotate
+//	keyctl revoke <kid>
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/config"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/oidc"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/repository"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	db, err := gorm.Open(postgres.Open(cfg.Database.DSN()), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+
+	keyManager := oidc.NewKeyManager(repository.NewSigningKeyRepository(db))
+
+	switch os.Args[1] {
+	case "rotate":
+		kid, err := keyManager.Rotate()
+		if err != nil {
+			log.Fatalf("rotate failed: %v", err)
+		}
+		fmt.Printf("rotated signing key, new kid=%s\n", kid)
+	case "revoke":
+		if len(os.Args) < 3 {
+			usage()
+		}
+		if err := keyManager.Revoke(os.Args[2]); err != nil {
+			log.Fatalf("revoke failed: %v", err)
+		}
+		fmt.Printf("revoked signing key kid=%s\n", os.Args[2])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: keyctl rotate | keyctl revoke <kid>")
+	os.Exit(1)
+}
@@ -0,0 +1,100 @@
+// Command rotate-keys re-wraps every envelope-encrypted value under a new
+// KEK without touching the ciphertext itself - the whole benefit of
+// envelope encryption (see internal/crypto/kms). Run this after deploying a
+// new MASTER_KEY_B64 so rows encrypted under the retired key stop depending
+// on it.
+//
+// Usage:
+//
+//	rotate-keys --old-master-key-b64=<previous MASTER_KEY_B64>
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/config"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/crypto/kms"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func main() {
+	oldMasterKeyB64 := flag.String("old-master-key-b64", "", "MASTER_KEY_B64 value the KEK is rotating away from")
+	flag.Parse()
+	if *oldMasterKeyB64 == "" {
+		log.Fatal("rotate-keys: --old-master-key-b64 is required")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	if cfg.KMS.Provider != "local" && cfg.KMS.Provider != "" {
+		log.Fatalf("rotate-keys only supports the local KMS provider today; got %q", cfg.KMS.Provider)
+	}
+
+	oldKeys, err := kms.NewLocalKeyProvider(*oldMasterKeyB64)
+	if err != nil {
+		log.Fatalf("failed to build old key provider: %v", err)
+	}
+	newKeys, err := kms.NewLocalKeyProvider(cfg.KMS.MasterKeyB64)
+	if err != nil {
+		log.Fatalf("failed to build new key provider: %v", err)
+	}
+
+	db, err := gorm.Open(postgres.Open(cfg.Database.DSN()), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+
+	rotated, err := rotateClientSecrets(db, oldKeys, newKeys)
+	if err != nil {
+		log.Fatalf("rotation failed: %v", err)
+	}
+	fmt.Printf("rewrapped %d oauth provider client secret(s)\n", rotated)
+}
+
+// rotateClientSecrets reads oauth_provider_configs.client_secret with a raw
+// query - bypassing the envelope GORM serializer, which would otherwise try
+// to decrypt it with the newly-registered key before we ever see the
+// ciphertext - and writes back each re-wrapped value with a plain UPDATE.
+func rotateClientSecrets(db *gorm.DB, oldKeys, newKeys kms.KeyProvider) (int, error) {
+	rows, err := db.Raw("SELECT id, client_secret FROM oauth_provider_configs WHERE client_secret IS NOT NULL AND client_secret != ''").Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	type pending struct {
+		id         string
+		ciphertext string
+	}
+	var toRotate []pending
+	for rows.Next() {
+		var id, ciphertext string
+		if err := rows.Scan(&id, &ciphertext); err != nil {
+			return 0, err
+		}
+		if kms.IsEncrypted(ciphertext) {
+			toRotate = append(toRotate, pending{id: id, ciphertext: ciphertext})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	ctx := context.Background()
+	for _, p := range toRotate {
+		rewrapped, err := kms.Rewrap(ctx, oldKeys, newKeys, p.ciphertext)
+		if err != nil {
+			return 0, fmt.Errorf("rewrap oauth_provider_configs %s: %w", p.id, err)
+		}
+		if err := db.Exec("UPDATE oauth_provider_configs SET client_secret = ? WHERE id = ?", rewrapped, p.id).Error; err != nil {
+			return 0, fmt.Errorf("update oauth_provider_configs %s: %w", p.id, err)
+		}
+	}
+	return len(toRotate), nil
+}
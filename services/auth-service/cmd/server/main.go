@@ -1,17 +1,32 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/exec"
+	"time"
 
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/audit"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/cache"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/config"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/crypto/kms"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/handler"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/middleware"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/oidc"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/repository"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/service"
+	"github.com/beegy-labs/my-girok/services/auth-service/plugin/userstorage"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	awskms "github.com/aws/aws-sdk-go-v2/service/kms"
+	gcpkms "cloud.google.com/go/kms/apiv1"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	hcplugin "github.com/hashicorp/go-plugin"
+	vaultapi "github.com/hashicorp/vault/api"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -29,6 +44,15 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Envelope-encrypted model fields (AuthProviderConfig.ClientSecret, see
+	// `serializer:envelope` tags) need the cipher registered before the
+	// first row touching one of them is read or written.
+	keyProvider, err := newKeyProvider(cfg.KMS)
+	if err != nil {
+		log.Fatalf("Failed to initialize KMS key provider: %v", err)
+	}
+	kms.RegisterGormSerializer(kms.NewEnvelopeCipher(keyProvider))
+
 	// Connect to database
 	db, err := connectDB(cfg)
 	if err != nil {
@@ -36,23 +60,89 @@ func main() {
 	}
 
 	// Auto migrate models
-	if err := db.AutoMigrate(&model.User{}, &model.Session{}, &model.OAuthProviderConfig{}); err != nil {
+	if err := db.AutoMigrate(
+		&model.User{},
+		&model.Session{},
+		&model.AuthProviderConfig{},
+		&model.OAuthClient{},
+		&model.AuthorizationCode{},
+		&model.AccessGrant{},
+		&model.SigningKey{},
+		&model.AuditLog{},
+		&model.DomainAccessToken{},
+		&model.OAuthState{},
+		&model.OAuthExchangeCode{},
+		&model.AccessToken{},
+	); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
-	// Initialize repositories
-	userRepo := repository.NewUserRepository(db)
-	sessionRepo := repository.NewSessionRepository(db)
+	// Initialize repositories. User storage can be swapped for a subprocess
+	// plugin (see plugin/userstorage) by setting USER_STORAGE_PLUGIN_CMD.
+	var userRepo repository.UserStore
+	if cfg.UserStorage.Cmd != "" {
+		userRepo, err = newPluginUserStore(cfg.UserStorage)
+		if err != nil {
+			log.Fatalf("Failed to load user storage plugin: %v", err)
+		}
+	} else {
+		userRepo = repository.NewUserRepository(db)
+	}
+	oauthClientRepo := repository.NewOAuthClientRepository(db)
+	authCodeRepo := repository.NewAuthorizationCodeRepository(db)
+	accessGrantRepo := repository.NewAccessGrantRepository(db)
+	signingKeyRepo := repository.NewSigningKeyRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	oauthConfigRepo := repository.NewAuthProviderConfigRepository(db)
+	oauthStateRepo := repository.NewOAuthStateRepository(db)
+	oauthExchangeCodeRepo := repository.NewOAuthExchangeCodeRepository(db)
+	accessTokenRepo := repository.NewAccessTokenRepository(db)
+
+	// Session and domain-access token lookups are fronted by CACHE_BACKEND
+	// (an in-process ristretto cache by default, Redis once REDIS_URL is
+	// set) so the hot read path doesn't hit Postgres on every request.
+	tokenCache, err := newTokenCache(cfg.Cache)
+	if err != nil {
+		log.Fatalf("Failed to initialize token cache: %v", err)
+	}
+	var sessionRepo repository.SessionStore = repository.NewCachedSessionRepository(repository.NewSessionRepository(db), tokenCache)
+	var domainAccessRepo repository.DomainAccessStore = repository.NewCachedDomainAccessRepository(repository.NewDomainAccessRepository(db), tokenCache)
+
+	// Audit events are always written to Postgres; AUDIT_SINK additionally
+	// streams a copy to a file or Kafka for SIEM ingestion.
+	auditSink, err := newAuditSink(cfg.Audit)
+	if err != nil {
+		log.Fatalf("Failed to initialize audit sink: %v", err)
+	}
+	auditLog := audit.NewLogger(auditLogRepo, auditSink)
 
 	// Initialize services
-	authService := service.NewAuthService(cfg, userRepo, sessionRepo)
-	oauthService := service.NewOAuthService(cfg, authService)
+	keyManager := oidc.NewKeyManager(signingKeyRepo)
+	authService := service.NewAuthService(cfg, userRepo, sessionRepo, auditLog, keyManager, oauthConfigRepo, tokenCache)
+	oauthService := service.NewOAuthService(cfg, authService, oauthConfigRepo, oauthStateRepo, oauthExchangeCodeRepo)
+	oauthConfigService := service.NewAuthProviderConfigService(oauthConfigRepo, auditLog)
+	oauthClientService := service.NewOAuthClientService(oauthClientRepo)
+	mfaService := service.NewMFAService(cfg, userRepo, auditLog, tokenCache)
+	accessTokenService := service.NewAccessTokenService(accessTokenRepo, userRepo, auditLog)
+	domainAccessService := service.NewDomainAccessService(cfg, domainAccessRepo, userRepo, auditLog)
+	oidcService := oidc.NewService(cfg, oauthClientRepo, authCodeRepo, accessGrantRepo, userRepo, keyManager, auditLog)
+
+	// Rotate the RS256 signing key on a schedule when JWT_KEY_ROTATION_INTERVAL
+	// is set; cmd/keyctl covers on-demand rotation and revocation.
+	if cfg.JWT.KeyRotationInterval > 0 {
+		go oidc.RunRotator(keyManager, cfg.JWT.KeyRotationInterval, nil)
+	}
 
 	// Initialize handlers
-	authHandler := handler.NewAuthHandler(authService)
+	authHandler := handler.NewAuthHandler(authService, mfaService)
 	oauthHandler := handler.NewOAuthHandler(oauthService, authService, cfg)
-	userHandler := handler.NewUserHandler(userRepo)
+	oauthConfigHandler := handler.NewAuthProviderConfigHandler(oauthConfigService)
+	oauthClientHandler := handler.NewOAuthClientHandler(oauthClientService)
+	userHandler := handler.NewUserHandler(userRepo, authService, mfaService, accessTokenService, auditLog, auditLogRepo)
+	domainAccessHandler := handler.NewDomainAccessHandler(domainAccessService)
+	auditHandler := handler.NewAuditHandler(auditLogRepo)
 	healthHandler := handler.NewHealthHandler(db)
+	oidcHandler := oidc.NewHandler(oidcService)
 
 	// Setup router
 	r := gin.Default()
@@ -70,34 +160,56 @@ func main() {
 	r.GET("/health", healthHandler.Health)
 	r.GET("/health/ready", healthHandler.Ready)
 
+	// OIDC discovery (no /v1 prefix, standard well-known paths)
+	r.GET("/.well-known/jwks.json", oidcHandler.JWKS)
+	r.GET("/.well-known/openid-configuration", oidcHandler.Discovery)
+
 	// API v1 routes
 	v1 := r.Group("/v1")
 
+	// Per-IP request limits against the brute-force surface these endpoints
+	// expose; account-level lockout for repeated failed Login attempts is
+	// handled inside AuthService itself (see ErrAccountLocked).
+	authRateLimit := middleware.RateLimitByIP(middleware.NewRateLimiter(10, time.Minute, tokenCache))
+
 	// Auth routes (public)
 	auth := v1.Group("/auth")
 	{
-		auth.POST("/register", authHandler.Register)
-		auth.POST("/login", authHandler.Login)
-		auth.POST("/refresh", authHandler.RefreshToken)
+		auth.POST("/register", authRateLimit, authHandler.Register)
+		auth.POST("/login", authRateLimit, authHandler.Login)
+		auth.POST("/refresh", authRateLimit, authHandler.RefreshToken)
 		auth.POST("/logout", authHandler.Logout)
+		auth.POST("/mfa/verify", authHandler.VerifyMFA)
+
+		// Upstream OAuth/OIDC providers, resolved by name from
+		// model.AuthProviderConfig rows rather than one route pair per IdP.
+		auth.GET("/oauth/providers", oauthConfigHandler.GetEnabledProviders)
+		auth.POST("/oauth/exchange", oauthHandler.Exchange)
+		auth.GET("/:provider/login", oauthHandler.Login)
+		auth.GET("/:provider/callback", oauthHandler.Callback)
 
-		// OAuth routes
-		auth.GET("/google", oauthHandler.GoogleLogin)
-		auth.GET("/google/callback", oauthHandler.GoogleCallback)
-		auth.GET("/kakao", oauthHandler.KakaoLogin)
-		auth.GET("/kakao/callback", oauthHandler.KakaoCallback)
-		auth.GET("/naver", oauthHandler.NaverLogin)
-		auth.GET("/naver/callback", oauthHandler.NaverCallback)
+		// Validated by other services holding a shared domain-access token
+		auth.GET("/domain-access/validate", domainAccessHandler.ValidateAccess)
 	}
 
 	// Protected routes
-	authMiddleware := middleware.AuthMiddleware(cfg.JWT.Secret)
+	authMiddleware := middleware.AuthMiddleware(cfg.JWT.Secret, keyManager, accessTokenService, cfg.JWT.DPoPRequired)
+
+	// Gates handlers sensitive enough that a bare access token isn't
+	// sufficient: the caller must have logged in within the last 15 minutes,
+	// or present a step_up token from a just-completed
+	// POST /v1/auth/reauthenticate. See middleware.RequireRecentAuth.
+	recentAuth := middleware.RequireRecentAuth(15*time.Minute, authService)
 
 	// Auth routes (protected)
 	authProtected := v1.Group("/auth")
 	authProtected.Use(authMiddleware)
 	{
 		authProtected.GET("/me", authHandler.Me)
+		authProtected.POST("/reauthenticate", authHandler.Reauthenticate)
+		authProtected.POST("/domain-access", recentAuth, domainAccessHandler.GrantAccess)
+		authProtected.GET("/domain-access", domainAccessHandler.GetMyTokens)
+		authProtected.DELETE("/domain-access/:domain", domainAccessHandler.RevokeAccess)
 	}
 
 	// User routes (protected)
@@ -107,7 +219,60 @@ func main() {
 		users.GET("", middleware.RoleMiddleware("MANAGER", "MASTER"), userHandler.List)
 		users.GET("/:id", userHandler.Get)
 		users.PATCH("/:id", userHandler.Update)
-		users.DELETE("/:id", middleware.RoleMiddleware("MASTER"), userHandler.Delete)
+		users.DELETE("/:id", middleware.RoleMiddleware("MASTER"), recentAuth, userHandler.Delete)
+		users.POST("/me/mfa/enroll", userHandler.EnrollMFA)
+		users.POST("/me/mfa/confirm", userHandler.ConfirmMFA)
+		users.DELETE("/me/mfa", recentAuth, userHandler.DisableMFA)
+		users.POST("/me/change-password", authRateLimit, recentAuth, userHandler.ChangePassword)
+		users.POST("/me/access-tokens", recentAuth, userHandler.CreateAccessToken)
+		users.GET("/me/access-tokens", userHandler.ListAccessTokens)
+		users.DELETE("/me/access-tokens/:tokenId", userHandler.RevokeAccessToken)
+		users.GET("/me/sessions", userHandler.GetMySessions)
+		users.DELETE("/me/sessions/:sessionId", userHandler.RevokeSession)
+		users.GET("/me/security/events", userHandler.GetMySecurityEvents)
+	}
+
+	// OAuth2/OIDC provider routes
+	oauthProvider := v1.Group("/oauth")
+	{
+		oauthProvider.GET("/authorize", authMiddleware, oidcHandler.Authorize)
+		oauthProvider.POST("/token", oidcHandler.Token)
+		oauthProvider.GET("/userinfo", oidcHandler.UserInfo)
+		oauthProvider.POST("/revoke", oidcHandler.Revoke)
+		oauthProvider.POST("/introspect", oidcHandler.Introspect)
+		oauthProvider.GET("/authorized-apps", authMiddleware, oidcHandler.AuthorizedApps)
+		oauthProvider.DELETE("/authorized-apps/:clientId", authMiddleware, oidcHandler.Deauthorize)
+	}
+
+	// Self-service OAuth client registration (the apps a user can authorize
+	// via the /oauth/authorize + /oauth/token flow above)
+	oauthClients := v1.Group("/oauth/clients")
+	oauthClients.Use(authMiddleware)
+	{
+		oauthClients.POST("", oauthClientHandler.Create)
+		oauthClients.GET("", oauthClientHandler.List)
+		oauthClients.GET("/:clientId", oauthClientHandler.Get)
+		oauthClients.PATCH("/:clientId", oauthClientHandler.Update)
+		oauthClients.POST("/:clientId/secret", oauthClientHandler.RegenerateSecret)
+		oauthClients.DELETE("/:clientId", oauthClientHandler.Delete)
+	}
+
+	// Admin routes
+	admin := v1.Group("/admin")
+	admin.Use(authMiddleware, middleware.RoleMiddleware("MANAGER", "MASTER"))
+	{
+		admin.GET("/audit", auditHandler.List)
+	}
+
+	// OAuth provider config management is gated on the admin:oauth-config:write
+	// scope rather than the coarser MANAGER/MASTER role check above.
+	adminOAuthConfig := v1.Group("/admin/oauth-config")
+	adminOAuthConfig.Use(authMiddleware, middleware.ScopeMiddleware("admin:oauth-config:write"))
+	{
+		adminOAuthConfig.GET("", oauthConfigHandler.GetProviders)
+		adminOAuthConfig.GET("/:provider", oauthConfigHandler.GetProvider)
+		adminOAuthConfig.PATCH("/:provider", oauthConfigHandler.UpdateProvider)
+		adminOAuthConfig.PATCH("/:provider/toggle", recentAuth, oauthConfigHandler.ToggleProvider)
 	}
 
 	// Start server
@@ -121,6 +286,94 @@ func main() {
 	}
 }
 
+// newPluginUserStore spawns the configured subprocess and dispenses its
+// "user_storage" plugin, adapting it to repository.UserStore.
+func newPluginUserStore(pc config.PluginConfig) (*repository.PluginUserStore, error) {
+	client := hcplugin.NewClient(&hcplugin.ClientConfig{
+		HandshakeConfig: userstorage.Handshake,
+		Plugins:         userstorage.PluginMap,
+		Cmd:             exec.Command(pc.Cmd, pc.Params...),
+		AllowedProtocols: []hcplugin.Protocol{
+			hcplugin.ProtocolGRPC,
+		},
+		SyncStdout: stdIfEnabled(pc.RedirectStd, os.Stdout),
+		SyncStderr: stdIfEnabled(pc.RedirectStd, os.Stderr),
+	})
+
+	rpcClient, err := client.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := rpcClient.Dispense("user_storage")
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.NewPluginUserStore(raw.(userstorage.Store)), nil
+}
+
+// newKeyProvider builds the KMS backend selected by KMS_PROVIDER, used to
+// wrap/unwrap the DEKs behind every `serializer:envelope` field.
+func newKeyProvider(cfg config.KMSConfig) (kms.KeyProvider, error) {
+	switch cfg.Provider {
+	case "aws":
+		awsCfg, err := awsconfig.LoadDefaultConfig(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("load aws config: %w", err)
+		}
+		return kms.NewAWSKeyProvider(awskms.NewFromConfig(awsCfg), cfg.AWSKeyID), nil
+	case "gcp":
+		client, err := gcpkms.NewKeyManagementClient(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("dial gcp kms: %w", err)
+		}
+		return kms.NewGCPKeyProvider(client, cfg.GCPKeyName), nil
+	case "vault":
+		client, err := vaultapi.NewClient(&vaultapi.Config{Address: cfg.VaultAddr})
+		if err != nil {
+			return nil, fmt.Errorf("create vault client: %w", err)
+		}
+		return kms.NewVaultKeyProvider(client, cfg.VaultKeyName), nil
+	case "local", "":
+		return kms.NewLocalKeyProvider(cfg.MasterKeyB64)
+	default:
+		return nil, fmt.Errorf("unknown KMS_PROVIDER %q", cfg.Provider)
+	}
+}
+
+// newTokenCache builds the cache backend selected by CACHE_BACKEND.
+func newTokenCache(cfg config.CacheConfig) (cache.Cache, error) {
+	switch cfg.Backend {
+	case "redis":
+		return cache.NewRedisCache(cfg.RedisURL)
+	case "memory", "":
+		return cache.NewMemoryCache()
+	default:
+		return nil, fmt.Errorf("unknown CACHE_BACKEND %q", cfg.Backend)
+	}
+}
+
+// newAuditSink builds the streaming sink selected by AUDIT_SINK. "none"
+// (the default) keeps audit events in Postgres only.
+func newAuditSink(cfg config.AuditConfig) (audit.Sink, error) {
+	switch cfg.Sink {
+	case "file":
+		return audit.NewFileSink(cfg.FilePath)
+	case "kafka":
+		return audit.NewKafkaSink(cfg.KafkaBrokers, cfg.KafkaTopic), nil
+	default:
+		return audit.NoopSink{}, nil
+	}
+}
+
+func stdIfEnabled(enabled bool, f *os.File) io.Writer {
+	if enabled {
+		return f
+	}
+	return nil
+}
+
 func connectDB(cfg *config.Config) (*gorm.DB, error) {
 	logLevel := logger.Silent
 	if cfg.Server.Environment == "development" {
@@ -0,0 +1,137 @@
+// Package audit records the forensic trail of authentication-relevant
+// events - who did what, to whom, from where - and exposes it to admins via
+// the repository's cursor-paginated query and, optionally, a streaming Sink.
+//
+// Scope note: the request that introduced this package also asked for a
+// general-purpose `events` package - an EventBus interface plus a typed
+// catalog (UserLoggedIn, OAuthLinked, RefreshTokenRotated, ...) publishers
+// could depend on independently of audit logging. That part was descoped;
+// Sink below only streams a copy of the raw AuditLog row to a SIEM/Kafka/file
+// target, it isn't a typed pub/sub layer other packages can subscribe to.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/repository"
+)
+
+// Event names written to AuditLog.Action. Kept as plain strings (rather
+// than a closed enum) so a streaming Sink consumer doesn't need this
+// package's types to filter on them.
+const (
+	ActionRegister          = "register"
+	ActionLoginSuccess      = "login_success"
+	ActionLoginFailure      = "login_failure"
+	ActionLogout            = "logout"
+	ActionRefresh           = "refresh"
+	ActionTokenRevoke       = "token_revoke"
+	ActionPasswordChange    = "password_change"
+	ActionRoleChange        = "role_change"
+	ActionOAuthLink         = "oauth_link"
+	ActionDomainGrant       = "domain_grant"
+	ActionDomainRevoke      = "domain_revoke"
+	ActionMFAEnroll         = "mfa_enroll"
+	ActionTokenReuse        = "token_reuse_detected"
+	ActionAppDeauthorize    = "app_deauthorize"
+	ActionProviderToggle    = "oauth_provider_toggle"
+	ActionAccessTokenCreate = "access_token_create"
+	ActionAccessTokenRevoke = "access_token_revoke"
+)
+
+// Logger writes audit entries to Postgres and, best-effort, to a streaming
+// Sink. A Sink or repository failure is logged and swallowed: a dropped
+// audit row must never fail the auth flow that triggered it, the same way
+// SessionRepository writes are treated elsewhere in this service.
+//
+// Each entry also carries Hash = sha256(PrevHash || canonical fields), chained
+// to the previous entry's Hash, so an admin comparing GET /v1/admin/audit-logs
+// against this chain can detect a row that was edited or deleted out from
+// under it. The chain head lives only in Postgres - CreateChained locks the
+// newest row for the duration of each write - so, unlike an in-memory
+// lastHash, this is safe with any number of Logger instances across any
+// number of replicas; none of them forks the chain by writing from a stale
+// head.
+type Logger struct {
+	repo *repository.AuditLogRepository
+	sink Sink
+}
+
+func NewLogger(repo *repository.AuditLogRepository, sink Sink) *Logger {
+	if sink == nil {
+		sink = NoopSink{}
+	}
+	return &Logger{repo: repo, sink: sink}
+}
+
+// Log records a single event. actorUserID is who performed the action
+// (empty for unauthenticated attempts, e.g. a failed login); targetUserID
+// is who it affected, which is usually actorUserID but differs for admin
+// actions such as a role change.
+func (l *Logger) Log(action, actorUserID, targetUserID, ip, userAgent string, metadata map[string]any) {
+	entry := &model.AuditLog{
+		ActorUserID:  actorUserID,
+		TargetUserID: targetUserID,
+		Action:       action,
+		IP:           ip,
+		UserAgent:    userAgent,
+		Metadata:     metadata,
+	}
+
+	// CreateChained locks the current chain head in Postgres for the
+	// duration of the write, so concurrent calls - even from other
+	// replicas - serialize on the row lock instead of both reading the
+	// same head and forking the chain, which would defeat chainHash's
+	// tamper-detection guarantee above.
+	err := l.repo.CreateChained(entry, func(prevHash string) {
+		entry.PrevHash = prevHash
+		entry.Hash = chainHash(entry)
+	})
+	if err != nil {
+		log.Printf("audit: failed to persist %q event: %v", action, err)
+		return
+	}
+
+	if err := l.sink.Write(entry); err != nil {
+		log.Printf("audit: failed to stream %q event: %v", action, err)
+	}
+}
+
+// chainHash hashes the fields a tamperer would want to change, plus
+// PrevHash, so altering or reordering any persisted row breaks the chain
+// from that point on. encoding/json sorts map keys, so this is stable
+// across runs despite Metadata being a map.
+func chainHash(entry *model.AuditLog) string {
+	canonical, _ := json.Marshal(struct {
+		PrevHash string         `json:"prevHash"`
+		Action   string         `json:"action"`
+		Actor    string         `json:"actor"`
+		Target   string         `json:"target"`
+		IP       string         `json:"ip"`
+		UA       string         `json:"ua"`
+		Metadata map[string]any `json:"metadata"`
+	}{entry.PrevHash, entry.Action, entry.ActorUserID, entry.TargetUserID, entry.IP, entry.UserAgent, entry.Metadata})
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyChain recomputes chainHash over entries, oldest-first, and reports
+// the index of the first row whose stored Hash/PrevHash don't match what
+// the chain should produce, or -1 if every row checks out. This is what
+// actually makes the tamper-detection the chain exists for usable - without
+// walking it, a broken link (a forked chain from a past data race, or a row
+// edited out from under Postgres directly) would sit undetected forever.
+func VerifyChain(entries []model.AuditLog) (brokenAt int) {
+	prevHash := ""
+	for i, entry := range entries {
+		if entry.PrevHash != prevHash || chainHash(&entry) != entry.Hash {
+			return i
+		}
+		prevHash = entry.Hash
+	}
+	return -1
+}
@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// Sink streams a copy of every audit entry somewhere outside the primary
+// Postgres table - a SIEM, a log aggregator, a compliance archive. The
+// repository write is always the source of truth; a Sink failure is logged
+// and swallowed rather than surfaced to the caller.
+type Sink interface {
+	Write(entry *model.AuditLog) error
+}
+
+// NoopSink is used when no AUDIT_SINK is configured.
+type NoopSink struct{}
+
+func (NoopSink) Write(*model.AuditLog) error { return nil }
+
+// FileSink appends each entry as a JSON line to a local file, e.g. for
+// shipping via a log-forwarding sidecar.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("audit: open sink file: %w", err)
+	}
+	return &FileSink{file: f}, nil
+}
+
+func (s *FileSink) Write(entry *model.AuditLog) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(line)
+	return err
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// KafkaSink publishes each entry as a JSON message to a single topic,
+// partitioned by actor so a given user's events stay ordered.
+type KafkaSink struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaSink(brokers []string, topic string) *KafkaSink {
+	return &KafkaSink{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    topic,
+			Balancer: &kafka.Hash{},
+		},
+	}
+}
+
+func (s *KafkaSink) Write(entry *model.AuditLog) error {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.writer.WriteMessages(context.Background(), kafka.Message{
+		Key:   []byte(entry.ActorUserID),
+		Value: value,
+	})
+}
+
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
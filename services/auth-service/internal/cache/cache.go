@@ -0,0 +1,43 @@
+// Package cache fronts the hot token-lookup paths (session and
+// domain-access tokens) with a pluggable, backend-agnostic store, the same
+// way plugin/userstorage makes user storage pluggable. CACHE_BACKEND selects
+// the implementation; a miss always falls through to Postgres, so a cache
+// outage degrades to the pre-cache latency rather than an outage.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is the storage contract a caching backend must satisfy. Values are
+// opaque strings (callers JSON-encode whatever they're caching) so the same
+// interface covers both a real Redis client and an in-process store.
+type Cache interface {
+	// Get reports found=false on a miss, distinct from err!=nil on a
+	// backend failure - callers should treat a failure the same as a miss
+	// (fall through to the source of truth) rather than surfacing it.
+	Get(ctx context.Context, key string) (value string, found bool, err error)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+
+	// Increment atomically adds 1 to the integer counter stored at key -
+	// creating it with value 1 and applying ttl if it doesn't exist yet -
+	// and returns the new value. A Get-then-Set round trip isn't safe for
+	// counters like failed-login lockouts: two concurrent callers can both
+	// read the same starting count and both write back count+1, losing one
+	// of the two failures. Backends implement this as a single atomic
+	// operation (Redis INCR, an in-process mutex) rather than composing it
+	// from Get/Set.
+	Increment(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
+
+// Negative is the sentinel value written for a confirmed-absent key, so a
+// repeated lookup of a token that doesn't exist is also served from cache
+// instead of hitting Postgres on every attempt - the defense against
+// token-scanning this package exists for.
+const Negative = "\x00negative"
+
+// NegativeTTL bounds how long a negative entry survives; kept short so a
+// token that's later created by a racing request isn't hidden for long.
+const NegativeTTL = 30 * time.Second
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+)
+
+// MemoryCache is the in-process fallback when no REDIS_URL is configured -
+// fine for a single instance or local development, but entries aren't
+// shared across replicas the way RedisCache's are.
+type MemoryCache struct {
+	store *ristretto.Cache
+
+	// counters backs Increment. It's a plain mutex-guarded map rather than
+	// going through store: ristretto applies Set/Get asynchronously via an
+	// internal buffer, which is fine for the cache's usual read-mostly
+	// token lookups but not for a counter that needs to read its own
+	// immediately-preceding write.
+	countersMu sync.Mutex
+	counters   map[string]counterEntry
+}
+
+type counterEntry struct {
+	value   int64
+	expires time.Time
+}
+
+// NewMemoryCache builds a bounded in-process cache. The NumCounters/MaxCost
+// figures follow ristretto's own sizing guidance (10x MaxCost counters).
+func NewMemoryCache() (*MemoryCache, error) {
+	store, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters: 1_000_000,
+		MaxCost:     100_000_000, // ~100MB of cached token entries
+		BufferItems: 64,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MemoryCache{store: store, counters: make(map[string]counterEntry)}, nil
+}
+
+func (c *MemoryCache) Get(_ context.Context, key string) (string, bool, error) {
+	v, found := c.store.Get(key)
+	if !found {
+		return "", false, nil
+	}
+	return v.(string), true, nil
+}
+
+func (c *MemoryCache) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	c.store.SetWithTTL(key, value, int64(len(value)), ttl)
+	return nil
+}
+
+func (c *MemoryCache) Delete(_ context.Context, key string) error {
+	c.store.Del(key)
+
+	c.countersMu.Lock()
+	delete(c.counters, key)
+	c.countersMu.Unlock()
+	return nil
+}
+
+func (c *MemoryCache) Increment(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	c.countersMu.Lock()
+	defer c.countersMu.Unlock()
+
+	entry, ok := c.counters[key]
+	if !ok || time.Now().After(entry.expires) {
+		entry = counterEntry{}
+	}
+	entry.value++
+	entry.expires = time.Now().Add(ttl)
+	c.counters[key] = entry
+	return entry.value, nil
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestMemoryCacheIncrementConcurrent pins down the fix for the lockout
+// undercounting bug: a Get-then-Set round trip lets concurrent callers read
+// the same starting count and lose updates, so N concurrent failures could
+// be recorded as fewer than N. Increment must account for every one of them.
+func TestMemoryCacheIncrementConcurrent(t *testing.T) {
+	c, err := NewMemoryCache()
+	if err != nil {
+		t.Fatalf("NewMemoryCache: %v", err)
+	}
+
+	const attempts = 50
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Increment(context.Background(), "key", time.Minute); err != nil {
+				t.Errorf("Increment: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	final, err := c.Increment(context.Background(), "key", time.Minute)
+	if err != nil {
+		t.Fatalf("Increment: %v", err)
+	}
+	if final != attempts+1 {
+		t.Errorf("got count %d after %d concurrent increments, want %d", final, attempts, attempts+1)
+	}
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache is the cache backend used once auth-service runs more than one
+// replica, so a token cached by the instance that issued it is visible to
+// whichever instance serves the next request.
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache parses rawURL (redis://[:password@]host:port/db) and dials
+// eagerly so a misconfigured REDIS_URL fails at startup, not on first use.
+func NewRedisCache(rawURL string) (*RedisCache, error) {
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+
+	return &RedisCache{client: client}, nil
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) (string, bool, error) {
+	value, err := c.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, key).Err()
+}
+
+// Increment uses Redis's own atomic INCR, then applies ttl only on the call
+// that created the key (n == 1) so repeated failures within the window
+// don't keep pushing the expiry back.
+func (c *RedisCache) Increment(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	n, err := c.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 {
+		if err := c.client.Expire(ctx, key, ttl).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
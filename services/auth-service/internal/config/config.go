@@ -1,17 +1,33 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/crypto/kms"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	OAuth    OAuthConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	JWT         JWTConfig
+	OAuth       OAuthConfig
+	App         AppConfig
+	UserStorage PluginConfig
+	Audit       AuditConfig
+	Cache       CacheConfig
+	KMS         KMSConfig
+	LDAP        LDAPConfig
+}
+
+// AppConfig holds settings for links this service renders back to users
+// (e.g. DomainAccessService.buildAccessURL), as opposed to OAuthConfig's
+// FrontendURL, which is specifically where upstream OAuth redirects land.
+type AppConfig struct {
+	BaseURL string
 }
 
 type ServerConfig struct {
@@ -40,22 +56,32 @@ type JWTConfig struct {
 	Secret             string
 	AccessTokenExpiry  time.Duration
 	RefreshTokenExpiry time.Duration
+	// DPoPRequired rejects every access token presented without a matching
+	// DPoP proof (RFC 9449). There is deliberately no per-client exemption
+	// knob: this service has no authenticated notion of "which client" is
+	// calling (first-party login tokens carry no client_id claim), so any
+	// such exemption could only be keyed on a self-reported value a caller
+	// fully controls, which would let anyone bypass the requirement just by
+	// claiming to be an exempt client. Roll DPoPRequired out gradually by
+	// leaving it false until every client in the fleet sends proofs.
+	DPoPRequired bool
+	// KeyRotationInterval is how often the RS256 signing key used for
+	// access/ID tokens is rotated automatically. Zero disables the
+	// background rotator; an operator can still rotate on demand via
+	// cmd/keyctl.
+	KeyRotationInterval time.Duration
 }
 
+// OAuthConfig holds the pieces of OAuth/OIDC setup that still come from the
+// environment. Upstream login providers themselves (Google, Kakao, Naver,
+// ...) are no longer configured here: they live as model.AuthProviderConfig
+// rows resolved by oauthupstream discovery, so enabling a new one is a DB
+// change, not a deploy.
 type OAuthConfig struct {
-	Google      OAuthProviderConfig
-	Kakao       OAuthProviderConfig
-	Naver       OAuthProviderConfig
 	Apple       AppleOAuthConfig
 	FrontendURL string
 }
 
-type OAuthProviderConfig struct {
-	ClientID     string
-	ClientSecret string
-	RedirectURL  string
-}
-
 type AppleOAuthConfig struct {
 	ClientID    string
 	TeamID      string
@@ -64,6 +90,62 @@ type AppleOAuthConfig struct {
 	RedirectURL string
 }
 
+// AuditConfig selects where audit events are streamed in addition to the
+// always-on Postgres write. Sink is one of "none" (default), "file", or
+// "kafka"; the matching fields below are only read for that sink.
+type AuditConfig struct {
+	Sink         string
+	FilePath     string
+	KafkaBrokers []string
+	KafkaTopic   string
+}
+
+// CacheConfig selects the backend fronting session/domain-access token
+// lookups (see internal/cache). Backend is one of "memory" (default, an
+// in-process ristretto cache) or "redis" (shared across replicas, requires
+// RedisURL).
+type CacheConfig struct {
+	Backend  string
+	RedisURL string
+}
+
+// KMSConfig selects the backend wrapping per-record data encryption keys
+// for internal/crypto/kms.EnvelopeCipher (used by the `serializer:envelope`
+// struct tag and cmd/rotate-keys). Provider is one of "local" (default,
+// AES-256-GCM keyed by MasterKeyB64), "aws", "gcp", or "vault". Load itself
+// only ever builds a local provider (see decryptIfEncrypted): by the time a
+// cloud KMS client could be dialed, Load would already need the very
+// secrets it's trying to decrypt.
+type KMSConfig struct {
+	Provider     string
+	MasterKeyB64 string
+	AWSKeyID     string
+	GCPKeyName   string
+	VaultAddr    string
+	VaultKeyName string
+}
+
+// LDAPConfig enables the "ldap" service.LoginProvider. A blank URL leaves it
+// unregistered, so the service stays local-only out of the box.
+type LDAPConfig struct {
+	URL            string
+	BindDN         string
+	BindPassword   string
+	UserSearchBase string
+	// UserFilter is an fmt-style filter template with a single %s for the
+	// escaped identifier, e.g. "(&(objectClass=inetOrgPerson)(mail=%s))".
+	UserFilter string
+}
+
+// PluginConfig points at a subprocess implementing a storage backend over
+// go-plugin (see plugin/userstorage). When Cmd is empty the service falls
+// back to its built-in GORM implementation.
+type PluginConfig struct {
+	Cmd         string
+	Params      []string
+	RedirectStd bool
+}
+
 func Load() (*Config, error) {
 	viper.AutomaticEnv()
 
@@ -84,12 +166,60 @@ func Load() (*Config, error) {
 	viper.SetDefault("JWT_SECRET", "your-secret-key-change-in-production")
 	viper.SetDefault("JWT_ACCESS_EXPIRY", "15m")
 	viper.SetDefault("JWT_REFRESH_EXPIRY", "168h")
+	viper.SetDefault("JWT_DPOP_REQUIRED", false)
+	viper.SetDefault("JWT_KEY_ROTATION_INTERVAL", "0")
 
 	// Frontend URL for OAuth callbacks
 	viper.SetDefault("FRONTEND_URL", "http://localhost:3000")
+	viper.SetDefault("APP_BASE_URL", "")
+
+	// Pluggable user-storage backend (empty USER_STORAGE_PLUGIN_CMD keeps the built-in GORM store)
+	viper.SetDefault("USER_STORAGE_PLUGIN_CMD", "")
+	viper.SetDefault("USER_STORAGE_PLUGIN_PARAMS", "")
+	viper.SetDefault("USER_STORAGE_PLUGIN_REDIRECT_STD", false)
+
+	// Audit log streaming sink ("none", "file", or "kafka")
+	viper.SetDefault("AUDIT_SINK", "none")
+	viper.SetDefault("AUDIT_FILE_PATH", "")
+	viper.SetDefault("AUDIT_KAFKA_BROKERS", "")
+	viper.SetDefault("AUDIT_KAFKA_TOPIC", "auth.audit")
+
+	// Session/domain-access token cache ("memory" or "redis")
+	viper.SetDefault("CACHE_BACKEND", "memory")
+	viper.SetDefault("REDIS_URL", "")
+
+	// Envelope-encryption KEK provider ("local", "aws", "gcp", or "vault").
+	// The default MASTER_KEY_B64 is a fixed, publicly-known key - exactly
+	// as insecure as the default JWT_SECRET above - so the service still
+	// boots out of the box; both must be overridden in production.
+	viper.SetDefault("KMS_PROVIDER", "local")
+	viper.SetDefault("MASTER_KEY_B64", "Z2lyb2stbG9jYWwtZGV2LW1hc3Rlci1rZXktMzJieSE=")
+	viper.SetDefault("KMS_AWS_KEY_ID", "")
+	viper.SetDefault("KMS_GCP_KEY_NAME", "")
+	viper.SetDefault("VAULT_ADDR", "")
+	viper.SetDefault("KMS_VAULT_KEY_NAME", "")
+
+	// LDAP login provider (see service.AuthService); blank LDAP_URL leaves
+	// it unregistered.
+	viper.SetDefault("LDAP_URL", "")
+	viper.SetDefault("LDAP_BIND_DN", "")
+	viper.SetDefault("LDAP_BIND_PASSWORD", "")
+	viper.SetDefault("LDAP_USER_SEARCH_BASE", "")
+	viper.SetDefault("LDAP_USER_FILTER", "(&(objectClass=inetOrgPerson)(mail=%s))")
 
 	accessExpiry, _ := time.ParseDuration(viper.GetString("JWT_ACCESS_EXPIRY"))
 	refreshExpiry, _ := time.ParseDuration(viper.GetString("JWT_REFRESH_EXPIRY"))
+	keyRotationInterval, _ := time.ParseDuration(viper.GetString("JWT_KEY_ROTATION_INTERVAL"))
+
+	masterKeyB64 := viper.GetString("MASTER_KEY_B64")
+	jwtSecret, err := decryptIfEncrypted(masterKeyB64, viper.GetString("JWT_SECRET"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt JWT_SECRET: %w", err)
+	}
+	applePrivateKey, err := decryptIfEncrypted(masterKeyB64, viper.GetString("APPLE_PRIVATE_KEY"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt APPLE_PRIVATE_KEY: %w", err)
+	}
 
 	// Parse CORS origins
 	originsStr := viper.GetString("CORS_ORIGINS")
@@ -98,6 +228,20 @@ func Load() (*Config, error) {
 		origins = splitAndTrim(originsStr, ",")
 	}
 
+	// Parse plugin params
+	pluginParamsStr := viper.GetString("USER_STORAGE_PLUGIN_PARAMS")
+	var pluginParams []string
+	if pluginParamsStr != "" {
+		pluginParams = splitAndTrim(pluginParamsStr, ",")
+	}
+
+	// Parse audit Kafka brokers
+	kafkaBrokersStr := viper.GetString("AUDIT_KAFKA_BROKERS")
+	var kafkaBrokers []string
+	if kafkaBrokersStr != "" {
+		kafkaBrokers = splitAndTrim(kafkaBrokersStr, ",")
+	}
+
 	return &Config{
 		Server: ServerConfig{
 			Port:         viper.GetString("PORT"),
@@ -113,38 +257,77 @@ func Load() (*Config, error) {
 			SSLMode:  viper.GetString("DB_SSL_MODE"),
 		},
 		JWT: JWTConfig{
-			Secret:             viper.GetString("JWT_SECRET"),
-			AccessTokenExpiry:  accessExpiry,
-			RefreshTokenExpiry: refreshExpiry,
+			Secret:              jwtSecret,
+			AccessTokenExpiry:   accessExpiry,
+			RefreshTokenExpiry:  refreshExpiry,
+			DPoPRequired:        viper.GetBool("JWT_DPOP_REQUIRED"),
+			KeyRotationInterval: keyRotationInterval,
 		},
 		OAuth: OAuthConfig{
 			FrontendURL: viper.GetString("FRONTEND_URL"),
-			Google: OAuthProviderConfig{
-				ClientID:     viper.GetString("GOOGLE_CLIENT_ID"),
-				ClientSecret: viper.GetString("GOOGLE_CLIENT_SECRET"),
-				RedirectURL:  viper.GetString("GOOGLE_REDIRECT_URL"),
-			},
-			Kakao: OAuthProviderConfig{
-				ClientID:     viper.GetString("KAKAO_CLIENT_ID"),
-				ClientSecret: viper.GetString("KAKAO_CLIENT_SECRET"),
-				RedirectURL:  viper.GetString("KAKAO_REDIRECT_URL"),
-			},
-			Naver: OAuthProviderConfig{
-				ClientID:     viper.GetString("NAVER_CLIENT_ID"),
-				ClientSecret: viper.GetString("NAVER_CLIENT_SECRET"),
-				RedirectURL:  viper.GetString("NAVER_REDIRECT_URL"),
-			},
 			Apple: AppleOAuthConfig{
 				ClientID:    viper.GetString("APPLE_CLIENT_ID"),
 				TeamID:      viper.GetString("APPLE_TEAM_ID"),
 				KeyID:       viper.GetString("APPLE_KEY_ID"),
-				PrivateKey:  viper.GetString("APPLE_PRIVATE_KEY"),
+				PrivateKey:  applePrivateKey,
 				RedirectURL: viper.GetString("APPLE_REDIRECT_URL"),
 			},
 		},
+		App: AppConfig{
+			BaseURL: viper.GetString("APP_BASE_URL"),
+		},
+		UserStorage: PluginConfig{
+			Cmd:         viper.GetString("USER_STORAGE_PLUGIN_CMD"),
+			Params:      pluginParams,
+			RedirectStd: viper.GetBool("USER_STORAGE_PLUGIN_REDIRECT_STD"),
+		},
+		Audit: AuditConfig{
+			Sink:         viper.GetString("AUDIT_SINK"),
+			FilePath:     viper.GetString("AUDIT_FILE_PATH"),
+			KafkaBrokers: kafkaBrokers,
+			KafkaTopic:   viper.GetString("AUDIT_KAFKA_TOPIC"),
+		},
+		Cache: CacheConfig{
+			Backend:  viper.GetString("CACHE_BACKEND"),
+			RedisURL: viper.GetString("REDIS_URL"),
+		},
+		KMS: KMSConfig{
+			Provider:     viper.GetString("KMS_PROVIDER"),
+			MasterKeyB64: masterKeyB64,
+			AWSKeyID:     viper.GetString("KMS_AWS_KEY_ID"),
+			GCPKeyName:   viper.GetString("KMS_GCP_KEY_NAME"),
+			VaultAddr:    viper.GetString("VAULT_ADDR"),
+			VaultKeyName: viper.GetString("KMS_VAULT_KEY_NAME"),
+		},
+		LDAP: LDAPConfig{
+			URL:            viper.GetString("LDAP_URL"),
+			BindDN:         viper.GetString("LDAP_BIND_DN"),
+			BindPassword:   viper.GetString("LDAP_BIND_PASSWORD"),
+			UserSearchBase: viper.GetString("LDAP_USER_SEARCH_BASE"),
+			UserFilter:     viper.GetString("LDAP_USER_FILTER"),
+		},
 	}, nil
 }
 
+// decryptIfEncrypted decrypts raw if it carries the "enc:v1:" envelope
+// prefix, using a LocalKeyProvider built from masterKeyB64 - the only
+// provider Load can use, since by the time a cloud KMS client could be
+// dialed, Load would already need the very secrets it's trying to decrypt.
+// Plaintext values pass through unchanged.
+func decryptIfEncrypted(masterKeyB64, raw string) (string, error) {
+	if !kms.IsEncrypted(raw) {
+		return raw, nil
+	}
+	if masterKeyB64 == "" {
+		return "", errors.New("value is enc:v1: encrypted but MASTER_KEY_B64 is not set")
+	}
+	provider, err := kms.NewLocalKeyProvider(masterKeyB64)
+	if err != nil {
+		return "", err
+	}
+	return kms.NewEnvelopeCipher(provider).Decrypt(context.Background(), raw)
+}
+
 func splitAndTrim(s, sep string) []string {
 	var result []string
 	for _, part := range split(s, sep) {
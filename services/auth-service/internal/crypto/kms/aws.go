@@ -0,0 +1,41 @@
+package kms
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// AWSKeyProvider wraps DEKs with an AWS KMS customer master key, identified
+// by keyID (a key ARN or an "alias/..." name).
+type AWSKeyProvider struct {
+	client *kms.Client
+	keyID  string
+}
+
+func NewAWSKeyProvider(client *kms.Client, keyID string) *AWSKeyProvider {
+	return &AWSKeyProvider{client: client, keyID: keyID}
+}
+
+func (p *AWSKeyProvider) Wrap(ctx context.Context, plaintext []byte) ([]byte, error) {
+	out, err := p.client.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:     aws.String(p.keyID),
+		Plaintext: plaintext,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.CiphertextBlob, nil
+}
+
+func (p *AWSKeyProvider) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	out, err := p.client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:          aws.String(p.keyID),
+		CiphertextBlob: wrapped,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
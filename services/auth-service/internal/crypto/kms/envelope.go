@@ -0,0 +1,153 @@
+package kms
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Prefix marks a value as EnvelopeCipher ciphertext, e.g. an env var or DB
+// column that may or may not be encrypted depending on deploy configuration.
+const Prefix = "enc:v1:"
+
+// IsEncrypted reports whether s is a value EnvelopeCipher produced.
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, Prefix)
+}
+
+// EnvelopeCipher seals values with a fresh per-call data encryption key
+// (DEK), then wraps that DEK under the configured KeyProvider's KEK -
+// standard envelope encryption, so rotating the KEK only means re-wrapping
+// the (tiny) DEKs already on disk via Rewrap, never re-encrypting the data
+// itself.
+type EnvelopeCipher struct {
+	keys KeyProvider
+}
+
+func NewEnvelopeCipher(keys KeyProvider) *EnvelopeCipher {
+	return &EnvelopeCipher{keys: keys}
+}
+
+// Encrypt seals plaintext into "enc:v1:<base64>", where the base64 payload
+// is [2-byte wrapped-DEK length][wrapped DEK][12-byte GCM nonce][ciphertext].
+func (e *EnvelopeCipher) Encrypt(ctx context.Context, plaintext string) (string, error) {
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	wrapped, err := e.keys.Wrap(ctx, dek)
+	if err != nil {
+		return "", fmt.Errorf("kms: wrap dek: %w", err)
+	}
+
+	gcm, err := newGCM(dek)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	ciphertext := gcm.Seal(nil, nonce, []byte(plaintext), nil)
+
+	payload, err := packPayload(wrapped, append(nonce, ciphertext...))
+	if err != nil {
+		return "", err
+	}
+	return Prefix + base64.StdEncoding.EncodeToString(payload), nil
+}
+
+// Decrypt reverses Encrypt.
+func (e *EnvelopeCipher) Decrypt(ctx context.Context, s string) (string, error) {
+	wrapped, rest, err := unpack(s)
+	if err != nil {
+		return "", err
+	}
+	dek, err := e.keys.Unwrap(ctx, wrapped)
+	if err != nil {
+		return "", fmt.Errorf("kms: unwrap dek: %w", err)
+	}
+	gcm, err := newGCM(dek)
+	if err != nil {
+		return "", err
+	}
+	if len(rest) < gcm.NonceSize() {
+		return "", errors.New("kms: truncated nonce")
+	}
+	nonce, ciphertext := rest[:gcm.NonceSize()], rest[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Rewrap re-wraps s's DEK under newKeys without touching the nonce or
+// ciphertext - the whole point of envelope encryption: rotating the KEK
+// costs one Unwrap+Wrap of a 32-byte key, not re-encrypting the record. Used
+// by cmd/rotate-keys when the KEK changes.
+func Rewrap(ctx context.Context, oldKeys, newKeys KeyProvider, s string) (string, error) {
+	wrapped, rest, err := unpack(s)
+	if err != nil {
+		return "", err
+	}
+	dek, err := oldKeys.Unwrap(ctx, wrapped)
+	if err != nil {
+		return "", fmt.Errorf("kms: unwrap dek with old key: %w", err)
+	}
+	newWrapped, err := newKeys.Wrap(ctx, dek)
+	if err != nil {
+		return "", fmt.Errorf("kms: wrap dek with new key: %w", err)
+	}
+	payload, err := packPayload(newWrapped, rest)
+	if err != nil {
+		return "", err
+	}
+	return Prefix + base64.StdEncoding.EncodeToString(payload), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// packPayload lays out [2-byte wrapped length][wrapped][rest].
+func packPayload(wrapped, rest []byte) ([]byte, error) {
+	if len(wrapped) > 0xFFFF {
+		return nil, errors.New("kms: wrapped dek too large")
+	}
+	payload := make([]byte, 0, 2+len(wrapped)+len(rest))
+	payload = append(payload, byte(len(wrapped)>>8), byte(len(wrapped)))
+	payload = append(payload, wrapped...)
+	payload = append(payload, rest...)
+	return payload, nil
+}
+
+// unpack decodes s and splits it into the wrapped DEK and the remaining
+// nonce+ciphertext bytes.
+func unpack(s string) (wrapped, rest []byte, err error) {
+	if !IsEncrypted(s) {
+		return nil, nil, errors.New("kms: not an envelope-encrypted value")
+	}
+	payload, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, Prefix))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(payload) < 2 {
+		return nil, nil, errors.New("kms: truncated payload")
+	}
+	wrappedLen := int(payload[0])<<8 | int(payload[1])
+	payload = payload[2:]
+	if len(payload) < wrappedLen {
+		return nil, nil, errors.New("kms: truncated wrapped dek")
+	}
+	return payload[:wrappedLen], payload[wrappedLen:], nil
+}
@@ -0,0 +1,42 @@
+package kms
+
+import (
+	"context"
+
+	kms "cloud.google.com/go/kms/apiv1"
+	"cloud.google.com/go/kms/apiv1/kmspb"
+)
+
+// GCPKeyProvider wraps DEKs with a Cloud KMS CryptoKey, identified by its
+// full resource name
+// ("projects/.../locations/.../keyRings/.../cryptoKeys/...").
+type GCPKeyProvider struct {
+	client  *kms.KeyManagementClient
+	keyName string
+}
+
+func NewGCPKeyProvider(client *kms.KeyManagementClient, keyName string) *GCPKeyProvider {
+	return &GCPKeyProvider{client: client, keyName: keyName}
+}
+
+func (p *GCPKeyProvider) Wrap(ctx context.Context, plaintext []byte) ([]byte, error) {
+	resp, err := p.client.Encrypt(ctx, &kmspb.EncryptRequest{
+		Name:      p.keyName,
+		Plaintext: plaintext,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Ciphertext, nil
+}
+
+func (p *GCPKeyProvider) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	resp, err := p.client.Decrypt(ctx, &kmspb.DecryptRequest{
+		Name:       p.keyName,
+		Ciphertext: wrapped,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
@@ -0,0 +1,18 @@
+// Package kms provides the KeyProvider abstraction behind EnvelopeCipher:
+// pluggable backends (local AES-256-GCM, AWS KMS, GCP KMS, Vault Transit)
+// for wrapping/unwrapping a per-record data encryption key (DEK), so the
+// choice of KEK custodian is a deploy-time config, not a code change -
+// mirrors audit.Sink and cache.Cache for exactly the same reason.
+package kms
+
+import "context"
+
+// KeyProvider wraps and unwraps a caller-generated data encryption key (DEK)
+// under a key-encryption key (KEK) the provider holds. EnvelopeCipher never
+// persists a DEK in plaintext - only the wrapped form Wrap returns.
+type KeyProvider interface {
+	// Wrap encrypts plaintext (a DEK) under the provider's KEK.
+	Wrap(ctx context.Context, plaintext []byte) (wrapped []byte, err error)
+	// Unwrap recovers the plaintext DEK from a previously wrapped one.
+	Unwrap(ctx context.Context, wrapped []byte) (plaintext []byte, err error)
+}
@@ -0,0 +1,53 @@
+package kms
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// LocalKeyProvider wraps DEKs with a KEK held in the process's own memory -
+// the default for local development and deployments without a dedicated KMS.
+// MASTER_KEY_B64 must decode to exactly 32 bytes (AES-256).
+type LocalKeyProvider struct {
+	gcm cipher.AEAD
+}
+
+func NewLocalKeyProvider(masterKeyB64 string) (*LocalKeyProvider, error) {
+	key, err := base64.StdEncoding.DecodeString(masterKeyB64)
+	if err != nil {
+		return nil, fmt.Errorf("kms: decode MASTER_KEY_B64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("kms: MASTER_KEY_B64 must decode to 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalKeyProvider{gcm: gcm}, nil
+}
+
+func (p *LocalKeyProvider) Wrap(_ context.Context, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, p.gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return p.gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (p *LocalKeyProvider) Unwrap(_ context.Context, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < p.gcm.NonceSize() {
+		return nil, errors.New("kms: wrapped dek shorter than nonce")
+	}
+	nonce, ciphertext := wrapped[:p.gcm.NonceSize()], wrapped[p.gcm.NonceSize():]
+	return p.gcm.Open(nil, nonce, ciphertext, nil)
+}
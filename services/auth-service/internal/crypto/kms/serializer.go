@@ -0,0 +1,69 @@
+package kms
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// GormSerializer adapts EnvelopeCipher to GORM's serializer extension point -
+// the same mechanism AuditLog.Metadata and DomainAccessToken.Scopes use for
+// "serializer:json" - so any field tagged `serializer:envelope` is
+// transparently encrypted on write and decrypted on read: plaintext in Go,
+// ciphertext in Postgres.
+type GormSerializer struct {
+	Cipher *EnvelopeCipher
+}
+
+var (
+	_ schema.SerializerInterface       = (*GormSerializer)(nil)
+	_ schema.SerializerValuerInterface = (*GormSerializer)(nil)
+)
+
+// RegisterGormSerializer wires cipher in under the "envelope" name,
+// activating every `serializer:envelope` struct tag. Must be called once at
+// startup before any encrypted-field model is read or written.
+func RegisterGormSerializer(cipher *EnvelopeCipher) {
+	schema.RegisterSerializer("envelope", &GormSerializer{Cipher: cipher})
+}
+
+func (s *GormSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	if dbValue == nil {
+		return nil
+	}
+	var raw string
+	switch v := dbValue.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("kms: unsupported db value type %T for envelope field %s", dbValue, field.Name)
+	}
+	if raw == "" {
+		return field.Set(ctx, dst, "")
+	}
+	if !IsEncrypted(raw) {
+		// A row written before encryption was enabled for this field; surface
+		// it as-is rather than failing every read.
+		return field.Set(ctx, dst, raw)
+	}
+	plaintext, err := s.Cipher.Decrypt(ctx, raw)
+	if err != nil {
+		return fmt.Errorf("kms: decrypt %s: %w", field.Name, err)
+	}
+	return field.Set(ctx, dst, plaintext)
+}
+
+func (s *GormSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("kms: envelope serializer only supports string fields, got %T for %s", fieldValue, field.Name)
+	}
+	if plaintext == "" {
+		return "", nil
+	}
+	return s.Cipher.Encrypt(ctx, plaintext)
+}
@@ -0,0 +1,123 @@
+// Package dpop implements enough of RFC 9449 (OAuth 2.0 Demonstrating
+// Proof-of-Possession) to bind access/refresh tokens to a client-held EC
+// key: verifying a DPoP proof JWT against the request it was attached to,
+// and computing the RFC 7638 JWK thumbprint used as a token's cnf.jkt.
+package dpop
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MaxProofAge bounds how stale a proof's iat may be, limiting the window in
+// which an intercepted proof JWT could be replayed (RFC 9449 section 11.1).
+const MaxProofAge = 5 * time.Minute
+
+var (
+	ErrMissingProof  = errors.New("missing dpop proof")
+	ErrInvalidProof  = errors.New("invalid dpop proof")
+	ErrProofMismatch = errors.New("dpop proof does not match request")
+	ErrProofExpired  = errors.New("dpop proof expired")
+)
+
+// jwk is the subset of RFC 7517 this package understands. DPoP clients in
+// this system are only expected to present EC P-256 keys.
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k *jwk) publicKey() (*ecdsa.PublicKey, error) {
+	if k.Kty != "EC" || k.Crv != "P-256" {
+		return nil, ErrInvalidProof
+	}
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, ErrInvalidProof
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, ErrInvalidProof
+	}
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
+
+// thumbprint computes the RFC 7638 JWK thumbprint: the base64url-encoded
+// SHA-256 digest of the key's required members, serialized with sorted
+// member names and no insignificant whitespace.
+func (k *jwk) thumbprint() string {
+	canonical, _ := json.Marshal(struct {
+		Crv string `json:"crv"`
+		Kty string `json:"kty"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}{Crv: k.Crv, Kty: k.Kty, X: k.X, Y: k.Y})
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+type proofClaims struct {
+	jwt.RegisteredClaims
+	HTM string `json:"htm"`
+	HTU string `json:"htu"`
+}
+
+// Verify checks that proof is a validly signed DPoP proof JWT bound to
+// method and url, and returns the thumbprint of the key that signed it. The
+// returned thumbprint is what callers compare against (or embed as) a
+// token's cnf.jkt claim.
+func Verify(proof, method, url string) (string, error) {
+	if proof == "" {
+		return "", ErrMissingProof
+	}
+
+	var key *jwk
+	token, err := jwt.ParseWithClaims(proof, &proofClaims{}, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != "ES256" {
+			return nil, ErrInvalidProof
+		}
+		if typ, _ := t.Header["typ"].(string); typ != "dpop+jwt" {
+			return nil, ErrInvalidProof
+		}
+
+		raw, err := json.Marshal(t.Header["jwk"])
+		if err != nil {
+			return nil, ErrInvalidProof
+		}
+		key = &jwk{}
+		if err := json.Unmarshal(raw, key); err != nil {
+			return nil, ErrInvalidProof
+		}
+		return key.publicKey()
+	})
+	if err != nil {
+		return "", ErrInvalidProof
+	}
+
+	claims, ok := token.Claims.(*proofClaims)
+	if !ok || !token.Valid {
+		return "", ErrInvalidProof
+	}
+	if claims.HTM != method || claims.HTU != url {
+		return "", ErrProofMismatch
+	}
+	if claims.IssuedAt == nil || time.Since(claims.IssuedAt.Time) > MaxProofAge {
+		return "", ErrProofExpired
+	}
+
+	return key.thumbprint(), nil
+}
@@ -0,0 +1,114 @@
+package dpop
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func mustKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+// signProofAs signs with signingKey but embeds embeddedKey's public
+// components in the jwk header - equal for every legitimate caller, but
+// lets TestVerifyWrongSigningKey construct a proof claiming to be signed by
+// a key it wasn't.
+func signProofAs(t *testing.T, signingKey, embeddedKey *ecdsa.PrivateKey, method, url string, iat time.Time) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, &proofClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt: jwt.NewNumericDate(iat),
+		},
+		HTM: method,
+		HTU: url,
+	})
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = map[string]string{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(embeddedKey.X.Bytes()),
+		"y":   base64.RawURLEncoding.EncodeToString(embeddedKey.Y.Bytes()),
+	}
+
+	signed, err := token.SignedString(signingKey)
+	if err != nil {
+		t.Fatalf("sign proof: %v", err)
+	}
+	return signed
+}
+
+func signProof(t *testing.T, key *ecdsa.PrivateKey, method, url string, iat time.Time) string {
+	t.Helper()
+	return signProofAs(t, key, key, method, url, iat)
+}
+
+func TestVerifyValidProof(t *testing.T) {
+	key := mustKey(t)
+	proof := signProof(t, key, "POST", "https://auth.example.com/v1/oauth/token", time.Now())
+
+	thumbprint, err := Verify(proof, "POST", "https://auth.example.com/v1/oauth/token")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if thumbprint == "" {
+		t.Error("got empty thumbprint for a valid proof")
+	}
+}
+
+func TestVerifyMissingProof(t *testing.T) {
+	if _, err := Verify("", "POST", "https://auth.example.com/v1/oauth/token"); err != ErrMissingProof {
+		t.Errorf("got err %v, want ErrMissingProof", err)
+	}
+}
+
+func TestVerifyMethodMismatch(t *testing.T) {
+	key := mustKey(t)
+	proof := signProof(t, key, "POST", "https://auth.example.com/v1/oauth/token", time.Now())
+
+	if _, err := Verify(proof, "GET", "https://auth.example.com/v1/oauth/token"); err != ErrProofMismatch {
+		t.Errorf("got err %v, want ErrProofMismatch", err)
+	}
+}
+
+func TestVerifyURLMismatch(t *testing.T) {
+	key := mustKey(t)
+	proof := signProof(t, key, "POST", "https://auth.example.com/v1/oauth/token", time.Now())
+
+	if _, err := Verify(proof, "POST", "https://auth.example.com/v1/oauth/other"); err != ErrProofMismatch {
+		t.Errorf("got err %v, want ErrProofMismatch", err)
+	}
+}
+
+func TestVerifyExpiredProof(t *testing.T) {
+	key := mustKey(t)
+	proof := signProof(t, key, "POST", "https://auth.example.com/v1/oauth/token", time.Now().Add(-MaxProofAge-time.Minute))
+
+	if _, err := Verify(proof, "POST", "https://auth.example.com/v1/oauth/token"); err != ErrProofExpired {
+		t.Errorf("got err %v, want ErrProofExpired", err)
+	}
+}
+
+// TestVerifyWrongSigningKey ensures a proof that embeds one key's public
+// components but was actually signed by a different private key is
+// rejected - otherwise an attacker could claim any thumbprint they like by
+// embedding someone else's public key in a proof they sign themselves.
+func TestVerifyWrongSigningKey(t *testing.T) {
+	signer := mustKey(t)
+	claimed := mustKey(t)
+	proof := signProofAs(t, signer, claimed, "POST", "https://auth.example.com/v1/oauth/token", time.Now())
+
+	if _, err := Verify(proof, "POST", "https://auth.example.com/v1/oauth/token"); err != ErrInvalidProof {
+		t.Errorf("got err %v, want ErrInvalidProof", err)
+	}
+}
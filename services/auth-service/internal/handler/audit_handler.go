@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditRepo *repository.AuditLogRepository
+}
+
+func NewAuditHandler(auditRepo *repository.AuditLogRepository) *AuditHandler {
+	return &AuditHandler{auditRepo: auditRepo}
+}
+
+// List returns audit log entries newest-first, filterable by actor, action,
+// and time range, with cursor pagination.
+// GET /v1/admin/audit
+func (h *AuditHandler) List(c *gin.Context) {
+	filter := repository.AuditLogFilter{
+		ActorUserID: c.Query("actorUserId"),
+		Action:      c.Query("action"),
+		Cursor:      c.Query("cursor"),
+	}
+
+	if limit, err := strconv.Atoi(c.DefaultQuery("limit", "20")); err == nil {
+		filter.Limit = limit
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be RFC3339"})
+			return
+		}
+		filter.From = t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be RFC3339"})
+			return
+		}
+		filter.To = t
+	}
+
+	entries, nextCursor, err := h.auditRepo.List(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": entries,
+		"meta": gin.H{
+			"nextCursor": nextCursor,
+		},
+	})
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/dpop"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/repository"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/service"
@@ -11,10 +12,11 @@ import (
 
 type AuthHandler struct {
 	authService *service.AuthService
+	mfaService  *service.MFAService
 }
 
-func NewAuthHandler(authService *service.AuthService) *AuthHandler {
-	return &AuthHandler{authService: authService}
+func NewAuthHandler(authService *service.AuthService, mfaService *service.MFAService) *AuthHandler {
+	return &AuthHandler{authService: authService, mfaService: mfaService}
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -24,12 +26,22 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.Register(&req)
+	dpopThumbprint, err := dpopThumbprintFromRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.authService.Register(&req, c.GetHeader("User-Agent"), c.ClientIP(), dpopThumbprint)
 	if err != nil {
 		if err == repository.ErrUserAlreadyExists {
 			c.JSON(http.StatusConflict, gin.H{"error": "user already exists"})
 			return
 		}
+		if err == service.ErrProviderDisabled {
+			c.JSON(http.StatusForbidden, gin.H{"error": "local registration is disabled"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to register user"})
 		return
 	}
@@ -47,19 +59,98 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	userAgent := c.GetHeader("User-Agent")
 	ip := c.ClientIP()
 
-	resp, err := h.authService.Login(&req, userAgent, ip)
+	dpopThumbprint, err := dpopThumbprintFromRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.authService.Login(&req, userAgent, ip, dpopThumbprint)
 	if err != nil {
 		switch err {
 		case service.ErrInvalidCredentials:
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 		case service.ErrUserNotActive:
 			c.JSON(http.StatusForbidden, gin.H{"error": "user is not active"})
+		case service.ErrProviderDisabled:
+			c.JSON(http.StatusForbidden, gin.H{"error": "authentication provider is disabled"})
+		case service.ErrAccountLocked:
+			_, retryAfter := h.authService.LoginLockout(req.Email)
+			c.JSON(http.StatusLocked, gin.H{"error": "too many failed login attempts", "retryAfter": int64(retryAfter.Seconds())})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to login"})
 		}
 		return
 	}
 
+	if result.MFAChallenge != nil {
+		c.JSON(http.StatusOK, result.MFAChallenge)
+		return
+	}
+
+	c.JSON(http.StatusOK, result.AuthResponse)
+}
+
+// VerifyMFA exchanges an mfa_pending token and a TOTP/recovery code for real tokens
+// POST /v1/auth/mfa/verify
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req model.VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dpopThumbprint, err := dpopThumbprintFromRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.authService.VerifyMFA(req.MFAToken, req.Code, h.mfaService, c.GetHeader("User-Agent"), c.ClientIP(), dpopThumbprint)
+	if err != nil {
+		switch err {
+		case service.ErrMFALocked:
+			c.JSON(http.StatusLocked, gin.H{"error": "too many failed mfa attempts"})
+		case service.ErrInvalidCredentials, service.ErrInvalidToken, service.ErrTokenExpired:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid mfa token or code"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify mfa"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Reauthenticate verifies a fresh password or TOTP code and issues a
+// short-lived step_up token used to gate sensitive operations
+// POST /v1/auth/reauthenticate
+func (h *AuthHandler) Reauthenticate(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req model.ReauthenticateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.authService.Reauthenticate(userID.(string), req.Password, req.Code, h.mfaService)
+	if err != nil {
+		switch err {
+		case service.ErrMFALocked:
+			c.JSON(http.StatusLocked, gin.H{"error": "too many failed mfa attempts"})
+		case service.ErrInvalidCredentials:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reauthenticate"})
+		}
+		return
+	}
+
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -70,11 +161,19 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.RefreshToken(req.RefreshToken)
+	dpopThumbprint, err := dpopThumbprintFromRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.authService.RefreshToken(req.RefreshToken, c.GetHeader("User-Agent"), c.ClientIP(), dpopThumbprint)
 	if err != nil {
 		switch err {
 		case service.ErrInvalidToken, service.ErrTokenExpired:
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+		case service.ErrTokenReplayed:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token reuse detected, please log in again"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to refresh token"})
 		}
@@ -91,7 +190,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
-	if err := h.authService.Logout(req.RefreshToken); err != nil {
+	if err := h.authService.Logout(req.RefreshToken, c.GetHeader("User-Agent"), c.ClientIP()); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to logout"})
 		return
 	}
@@ -114,3 +213,24 @@ func (h *AuthHandler) Me(c *gin.Context) {
 
 	c.JSON(http.StatusOK, user.ToResponse())
 }
+
+// dpopThumbprintFromRequest verifies an optional DPoP proof header against
+// this request and returns the thumbprint of the key that signed it, to be
+// bound into the access token this call issues. Returns "" (no error) when
+// the client sent no DPoP header at all; a present-but-invalid proof is a
+// client error.
+func dpopThumbprintFromRequest(c *gin.Context) (string, error) {
+	proof := c.GetHeader("DPoP")
+	if proof == "" {
+		return "", nil
+	}
+	return dpop.Verify(proof, c.Request.Method, requestURL(c))
+}
+
+func requestURL(c *gin.Context) string {
+	scheme := "https"
+	if c.Request.TLS == nil {
+		scheme = "http"
+	}
+	return scheme + "://" + c.Request.Host + c.Request.URL.Path
+}
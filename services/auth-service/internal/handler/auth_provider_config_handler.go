@@ -9,26 +9,26 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-type OAuthConfigHandler struct {
-	oauthConfigService *service.OAuthConfigService
+type AuthProviderConfigHandler struct {
+	oauthConfigService *service.AuthProviderConfigService
 }
 
-func NewOAuthConfigHandler(oauthConfigService *service.OAuthConfigService) *OAuthConfigHandler {
-	return &OAuthConfigHandler{
+func NewAuthProviderConfigHandler(oauthConfigService *service.AuthProviderConfigService) *AuthProviderConfigHandler {
+	return &AuthProviderConfigHandler{
 		oauthConfigService: oauthConfigService,
 	}
 }
 
 // GetProviders returns all OAuth provider configurations
 // GET /v1/admin/oauth-config
-func (h *OAuthConfigHandler) GetProviders(c *gin.Context) {
+func (h *AuthProviderConfigHandler) GetProviders(c *gin.Context) {
 	configs, err := h.oauthConfigService.GetAllProviders()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get providers"})
 		return
 	}
 
-	var response []model.OAuthProviderConfigResponse
+	var response []model.AuthProviderConfigResponse
 	for _, config := range configs {
 		response = append(response, config.ToResponse())
 	}
@@ -38,7 +38,7 @@ func (h *OAuthConfigHandler) GetProviders(c *gin.Context) {
 
 // GetEnabledProviders returns only enabled OAuth providers (public endpoint)
 // GET /v1/auth/oauth/providers
-func (h *OAuthConfigHandler) GetEnabledProviders(c *gin.Context) {
+func (h *AuthProviderConfigHandler) GetEnabledProviders(c *gin.Context) {
 	configs, err := h.oauthConfigService.GetEnabledProviders()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get providers"})
@@ -58,13 +58,13 @@ func (h *OAuthConfigHandler) GetEnabledProviders(c *gin.Context) {
 
 // GetProvider returns a specific OAuth provider configuration
 // GET /v1/admin/oauth-config/:provider
-func (h *OAuthConfigHandler) GetProvider(c *gin.Context) {
+func (h *AuthProviderConfigHandler) GetProvider(c *gin.Context) {
 	providerStr := c.Param("provider")
 	provider := model.AuthProvider(providerStr)
 
 	config, err := h.oauthConfigService.GetProvider(provider)
 	if err != nil {
-		if err == repository.ErrOAuthConfigNotFound {
+		if err == repository.ErrAuthProviderConfigNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "provider not found"})
 			return
 		}
@@ -77,7 +77,7 @@ func (h *OAuthConfigHandler) GetProvider(c *gin.Context) {
 
 // ToggleProvider enables or disables an OAuth provider
 // PATCH /v1/admin/oauth-config/:provider/toggle
-func (h *OAuthConfigHandler) ToggleProvider(c *gin.Context) {
+func (h *AuthProviderConfigHandler) ToggleProvider(c *gin.Context) {
 	providerStr := c.Param("provider")
 	provider := model.AuthProvider(providerStr)
 
@@ -100,7 +100,7 @@ func (h *OAuthConfigHandler) ToggleProvider(c *gin.Context) {
 		return
 	}
 
-	config, err := h.oauthConfigService.ToggleProvider(provider, req.Enabled, userID.(string))
+	config, err := h.oauthConfigService.ToggleProvider(provider, req.Enabled, userID.(string), c.ClientIP(), c.GetHeader("User-Agent"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to toggle provider"})
 		return
@@ -111,7 +111,7 @@ func (h *OAuthConfigHandler) ToggleProvider(c *gin.Context) {
 
 // UpdateProvider updates OAuth provider configuration
 // PATCH /v1/admin/oauth-config/:provider
-func (h *OAuthConfigHandler) UpdateProvider(c *gin.Context) {
+func (h *AuthProviderConfigHandler) UpdateProvider(c *gin.Context) {
 	providerStr := c.Param("provider")
 	provider := model.AuthProvider(providerStr)
 
@@ -122,9 +122,15 @@ func (h *OAuthConfigHandler) UpdateProvider(c *gin.Context) {
 	}
 
 	var req struct {
-		DisplayName string `json:"displayName"`
-		Description string `json:"description"`
-		CallbackURL string `json:"callbackUrl"`
+		DisplayName     string `json:"displayName"`
+		Description     string `json:"description"`
+		CallbackURL     string `json:"callbackUrl"`
+		ClientID        string `json:"clientId"`
+		ClientSecret    string `json:"clientSecret"`
+		IssuerURL       string `json:"issuerUrl"`
+		Scopes          string `json:"scopes"`
+		UserInfoMapping string `json:"userInfoMapping"`
+		RoleMapping     string `json:"roleMapping"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -138,15 +144,19 @@ func (h *OAuthConfigHandler) UpdateProvider(c *gin.Context) {
 		return
 	}
 
-	config, err := h.oauthConfigService.UpdateProviderConfig(
-		provider,
-		req.DisplayName,
-		req.Description,
-		req.CallbackURL,
-		userID.(string),
-	)
+	config, err := h.oauthConfigService.UpdateProviderConfig(provider, service.ProviderConfigUpdate{
+		DisplayName:     req.DisplayName,
+		Description:     req.Description,
+		CallbackURL:     req.CallbackURL,
+		ClientID:        req.ClientID,
+		ClientSecret:    req.ClientSecret,
+		IssuerURL:       req.IssuerURL,
+		Scopes:          req.Scopes,
+		UserInfoMapping: req.UserInfoMapping,
+		RoleMapping:     req.RoleMapping,
+	}, userID.(string))
 	if err != nil {
-		if err == repository.ErrOAuthConfigNotFound {
+		if err == repository.ErrAuthProviderConfigNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "provider not found"})
 			return
 		}
@@ -157,12 +167,9 @@ func (h *OAuthConfigHandler) UpdateProvider(c *gin.Context) {
 	c.JSON(http.StatusOK, config.ToResponse())
 }
 
+// isValidProvider rejects only the empty provider; every other identifier,
+// including the built-in LOCAL, is a legitimate AuthProviderConfig row to
+// toggle - disabling LOCAL is what puts the service into SSO-only mode.
 func isValidProvider(provider model.AuthProvider) bool {
-	validProviders := map[model.AuthProvider]bool{
-		model.ProviderGoogle: true,
-		model.ProviderKakao:  true,
-		model.ProviderNaver:  true,
-		model.ProviderApple:  true,
-	}
-	return validProviders[provider]
+	return provider != ""
 }
@@ -33,7 +33,7 @@ func (h *DomainAccessHandler) GrantAccess(c *gin.Context) {
 		return
 	}
 
-	token, accessURL, err := h.domainAccessService.GrantAccess(userID.(string), req.Domain, req.ExpiresInHours)
+	token, accessURL, err := h.domainAccessService.GrantAccess(userID.(string), req.Domain, req.ExpiresInHours, req.Scopes, c.GetHeader("User-Agent"), c.ClientIP())
 	if err != nil {
 		switch err {
 		case service.ErrInvalidDomain:
@@ -48,6 +48,7 @@ func (h *DomainAccessHandler) GrantAccess(c *gin.Context) {
 		AccessToken: token.Token,
 		ExpiresAt:   token.ExpiresAt,
 		AccessURL:   accessURL,
+		Scopes:      token.Scopes,
 	})
 }
 
@@ -62,7 +63,7 @@ func (h *DomainAccessHandler) ValidateAccess(c *gin.Context) {
 		return
 	}
 
-	user, err := h.domainAccessService.ValidateAccess(token, domain)
+	user, scopes, err := h.domainAccessService.ValidateAccess(token, domain)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
 		return
@@ -75,6 +76,7 @@ func (h *DomainAccessHandler) ValidateAccess(c *gin.Context) {
 		"externalId": user.ExternalID,
 		"name":       user.Name,
 		"email":      user.Email,
+		"scopes":     scopes.String(),
 	})
 }
 
@@ -93,7 +95,7 @@ func (h *DomainAccessHandler) RevokeAccess(c *gin.Context) {
 		return
 	}
 
-	err := h.domainAccessService.RevokeAccess(userID.(string), domain)
+	err := h.domainAccessService.RevokeAccess(userID.(string), domain, c.GetHeader("User-Agent"), c.ClientIP())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke access"})
 		return
@@ -123,6 +125,7 @@ func (h *DomainAccessHandler) GetMyTokens(c *gin.Context) {
 		response = append(response, gin.H{
 			"id":        t.ID,
 			"domain":    t.Domain,
+			"scopes":    t.Scopes,
 			"expiresAt": t.ExpiresAt,
 			"createdAt": t.CreatedAt,
 		})
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HealthHandler backs the liveness/readiness endpoints infrastructure
+// (load balancers, k8s probes) polls; neither requires auth since they run
+// before a caller could have a session.
+type HealthHandler struct {
+	db *gorm.DB
+}
+
+func NewHealthHandler(db *gorm.DB) *HealthHandler {
+	return &HealthHandler{db: db}
+}
+
+// Health reports the process is up, regardless of its dependencies.
+// GET /health
+func (h *HealthHandler) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready additionally checks the database is reachable, since a process
+// that's up but can't reach Postgres shouldn't receive traffic.
+// GET /health/ready
+func (h *HealthHandler) Ready(c *gin.Context) {
+	sqlDB, err := h.db.DB()
+	if err != nil || sqlDB.Ping() != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
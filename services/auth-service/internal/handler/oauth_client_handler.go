@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/repository"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthClientHandler lets a signed-in user register and manage the OAuth2
+// clients that log in through auth-service's own authorization-code flow
+// (see internal/oidc), alongside AuthProviderConfigHandler which manages the
+// upstream IdPs auth-service federates to.
+type OAuthClientHandler struct {
+	clientService *service.OAuthClientService
+}
+
+func NewOAuthClientHandler(clientService *service.OAuthClientService) *OAuthClientHandler {
+	return &OAuthClientHandler{clientService: clientService}
+}
+
+type createOAuthClientRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	RedirectURIs []string `json:"redirectUris" binding:"required,min=1"`
+	Scopes       []string `json:"scopes"`
+	GrantTypes   []string `json:"grantTypes"`
+}
+
+// Create registers a new OAuth client owned by the caller.
+// POST /v1/oauth/clients
+func (h *OAuthClientHandler) Create(c *gin.Context) {
+	var req createOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(string)
+	client, secret, err := h.clientService.Create(userID, req.Name, req.RedirectURIs, req.Scopes, req.GrantTypes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create oauth client"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.OAuthClientSecretResponse{
+		OAuthClientResponse: client.ToResponse(),
+		ClientSecret:        secret,
+	})
+}
+
+// List returns every OAuth client owned by the caller.
+// GET /v1/oauth/clients
+func (h *OAuthClientHandler) List(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	clients, err := h.clientService.ListByOwner(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list oauth clients"})
+		return
+	}
+
+	responses := make([]model.OAuthClientResponse, 0, len(clients))
+	for _, client := range clients {
+		responses = append(responses, client.ToResponse())
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// Get returns one OAuth client owned by the caller.
+// GET /v1/oauth/clients/:clientId
+func (h *OAuthClientHandler) Get(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	client, err := h.clientService.Get(c.Param("clientId"), userID)
+	if err != nil {
+		h.handleOwnershipError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, client.ToResponse())
+}
+
+type updateOAuthClientRequest struct {
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirectUris"`
+	Scopes       []string `json:"scopes"`
+}
+
+// Update patches name, redirect URIs, or scopes on a client owned by the caller.
+// PATCH /v1/oauth/clients/:clientId
+func (h *OAuthClientHandler) Update(c *gin.Context) {
+	var req updateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("userID").(string)
+	client, err := h.clientService.Update(c.Param("clientId"), userID, req.Name, req.RedirectURIs, req.Scopes)
+	if err != nil {
+		h.handleOwnershipError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, client.ToResponse())
+}
+
+// RegenerateSecret issues a new client secret, returned once in the response.
+// POST /v1/oauth/clients/:clientId/secret
+func (h *OAuthClientHandler) RegenerateSecret(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	secret, err := h.clientService.RegenerateSecret(c.Param("clientId"), userID)
+	if err != nil {
+		h.handleOwnershipError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"clientSecret": secret})
+}
+
+// Delete removes a client owned by the caller.
+// DELETE /v1/oauth/clients/:clientId
+func (h *OAuthClientHandler) Delete(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	if err := h.clientService.Delete(c.Param("clientId"), userID); err != nil {
+		h.handleOwnershipError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "oauth client deleted"})
+}
+
+func (h *OAuthClientHandler) handleOwnershipError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrOAuthClientForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "not your oauth client"})
+	case errors.Is(err, repository.ErrOAuthClientNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "oauth client not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process oauth client"})
+	}
+}
@@ -1,12 +1,12 @@
 package handler
 
 import (
-	"crypto/rand"
-	"encoding/hex"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/config"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/service"
 	"github.com/gin-gonic/gin"
 )
@@ -25,108 +25,100 @@ func NewOAuthHandler(oauthService *service.OAuthService, authService *service.Au
 	}
 }
 
-func generateState() string {
-	b := make([]byte, 16)
-	rand.Read(b)
-	return hex.EncodeToString(b)
-}
-
-func (h *OAuthHandler) redirectWithTokens(c *gin.Context, accessToken, refreshToken string) {
+// redirectWithExchangeCode hands the browser a one-time code instead of the
+// tokens themselves, so they never appear in a URL that ends up in browser
+// history, a Referer header, or a proxy access log. The frontend redeems it
+// immediately via Exchange.
+func (h *OAuthHandler) redirectWithExchangeCode(c *gin.Context, accessToken, refreshToken string) {
+	code, err := h.oauthService.MintExchangeCode(accessToken, refreshToken)
+	if err != nil {
+		h.redirectWithError(c, "failed to finalize login")
+		return
+	}
 	u, _ := url.Parse(h.cfg.OAuth.FrontendURL)
 	q := u.Query()
-	q.Set("access_token", accessToken)
-	q.Set("refresh_token", refreshToken)
+	q.Set("code", code)
 	u.RawQuery = q.Encode()
 	c.Redirect(http.StatusTemporaryRedirect, u.String())
 }
 
-// Google OAuth
-func (h *OAuthHandler) GoogleLogin(c *gin.Context) {
-	state := generateState()
-	c.SetCookie("oauth_state", state, 600, "/", "", false, true)
-	authURL := h.oauthService.GetGoogleAuthURL(state)
-	c.Redirect(http.StatusTemporaryRedirect, authURL)
+// redirectWithError sends the browser back to the frontend's error page
+// instead of returning JSON, so every provider's failure mode resolves to
+// the same uniform flow for the SPA to render.
+func (h *OAuthHandler) redirectWithError(c *gin.Context, message string) {
+	u, _ := url.Parse(h.cfg.OAuth.FrontendURL)
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/error"
+	q := u.Query()
+	q.Set("message", message)
+	u.RawQuery = q.Encode()
+	c.Redirect(http.StatusTemporaryRedirect, u.String())
 }
 
-func (h *OAuthHandler) GoogleCallback(c *gin.Context) {
-	code := c.Query("code")
-	if code == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing code"})
-		return
-	}
+// Login redirects to the upstream authorization endpoint for any enabled,
+// discovery-configured provider.
+// GET /v1/auth/:provider/login
+func (h *OAuthHandler) Login(c *gin.Context) {
+	provider := model.AuthProvider(strings.ToUpper(c.Param("provider")))
 
-	user, err := h.oauthService.HandleGoogleCallback(c.Request.Context(), code)
+	authURL, err := h.oauthService.GetAuthURL(c.Request.Context(), provider)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "oauth failed: " + err.Error()})
+		h.redirectWithError(c, "oauth login unavailable: "+err.Error())
 		return
 	}
 
-	resp, err := h.authService.GenerateOAuthResponse(user, c.GetHeader("User-Agent"), c.ClientIP())
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate tokens"})
-		return
-	}
-
-	h.redirectWithTokens(c, resp.AccessToken, resp.RefreshToken)
-}
-
-// Kakao OAuth
-func (h *OAuthHandler) KakaoLogin(c *gin.Context) {
-	state := generateState()
-	c.SetCookie("oauth_state", state, 600, "/", "", false, true)
-	authURL := h.oauthService.GetKakaoAuthURL(state)
 	c.Redirect(http.StatusTemporaryRedirect, authURL)
 }
 
-func (h *OAuthHandler) KakaoCallback(c *gin.Context) {
+// Callback exchanges the authorization code for tokens and logs the user
+// in, for any enabled, discovery-configured provider.
+// GET /v1/auth/:provider/callback
+func (h *OAuthHandler) Callback(c *gin.Context) {
+	provider := model.AuthProvider(strings.ToUpper(c.Param("provider")))
 	code := c.Query("code")
+	state := c.Query("state")
 	if code == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing code"})
+		h.redirectWithError(c, "missing code")
 		return
 	}
 
-	user, err := h.oauthService.HandleKakaoCallback(c.Request.Context(), code)
+	user, err := h.oauthService.HandleCallback(c.Request.Context(), provider, code, state, c.GetHeader("User-Agent"), c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "oauth failed: " + err.Error()})
+		h.redirectWithError(c, "oauth failed: "+err.Error())
 		return
 	}
 
-	resp, err := h.authService.GenerateOAuthResponse(user, c.GetHeader("User-Agent"), c.ClientIP())
+	resp, err := h.authService.GenerateOAuthResponse(user, c.GetHeader("User-Agent"), c.ClientIP(), "")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate tokens"})
+		h.redirectWithError(c, "failed to generate tokens")
 		return
 	}
 
-	h.redirectWithTokens(c, resp.AccessToken, resp.RefreshToken)
+	h.redirectWithExchangeCode(c, resp.AccessToken, resp.RefreshToken)
 }
 
-// Naver OAuth
-func (h *OAuthHandler) NaverLogin(c *gin.Context) {
-	state := generateState()
-	c.SetCookie("oauth_state", state, 600, "/", "", false, true)
-	authURL := h.oauthService.GetNaverAuthURL(state)
-	c.Redirect(http.StatusTemporaryRedirect, authURL)
+type exchangeCodeRequest struct {
+	Code string `json:"code" binding:"required"`
 }
 
-func (h *OAuthHandler) NaverCallback(c *gin.Context) {
-	code := c.Query("code")
-	state := c.Query("state")
-	if code == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing code"})
-		return
-	}
-
-	user, err := h.oauthService.HandleNaverCallback(c.Request.Context(), code, state)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "oauth failed: " + err.Error()})
+// Exchange redeems the one-time code from Callback's redirect for the real
+// access/refresh tokens, over a POST body rather than a URL.
+// POST /v1/auth/oauth/exchange
+func (h *OAuthHandler) Exchange(c *gin.Context) {
+	var req exchangeCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	resp, err := h.authService.GenerateOAuthResponse(user, c.GetHeader("User-Agent"), c.ClientIP())
+	accessToken, refreshToken, err := h.oauthService.ConsumeExchangeCode(req.Code)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate tokens"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired exchange code"})
 		return
 	}
 
-	h.redirectWithTokens(c, resp.AccessToken, resp.RefreshToken)
+	c.JSON(http.StatusOK, model.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(h.cfg.JWT.AccessTokenExpiry.Seconds()),
+	})
 }
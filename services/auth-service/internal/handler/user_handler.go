@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/audit"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/repository"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/service"
@@ -11,14 +12,22 @@ import (
 )
 
 type UserHandler struct {
-	userRepo    *repository.UserRepository
-	authService *service.AuthService
+	userRepo           repository.UserStore
+	authService        *service.AuthService
+	mfaService         *service.MFAService
+	accessTokenService *service.AccessTokenService
+	auditLog           *audit.Logger
+	auditRepo          *repository.AuditLogRepository
 }
 
-func NewUserHandler(userRepo *repository.UserRepository, authService *service.AuthService) *UserHandler {
+func NewUserHandler(userRepo repository.UserStore, authService *service.AuthService, mfaService *service.MFAService, accessTokenService *service.AccessTokenService, auditLog *audit.Logger, auditRepo *repository.AuditLogRepository) *UserHandler {
 	return &UserHandler{
-		userRepo:    userRepo,
-		authService: authService,
+		userRepo:           userRepo,
+		authService:        authService,
+		mfaService:         mfaService,
+		accessTokenService: accessTokenService,
+		auditLog:           auditLog,
+		auditRepo:          auditRepo,
 	}
 }
 
@@ -118,6 +127,16 @@ func (h *UserHandler) Update(c *gin.Context) {
 		user.Picture = *req.Picture
 	}
 
+	// Role is an admin-only field: only a MANAGER/MASTER editing someone
+	// else's account may change it.
+	var previousRole model.Role
+	roleChanged := false
+	if req.Role != nil && currentUserID != id && (role == "MANAGER" || role == "MASTER") && *req.Role != user.Role {
+		previousRole = user.Role
+		roleChanged = true
+		user.Role = *req.Role
+	}
+
 	if err := h.userRepo.Update(user); err != nil {
 		if err == repository.ErrUserAlreadyExists {
 			c.JSON(http.StatusConflict, gin.H{"error": "username already taken"})
@@ -127,6 +146,13 @@ func (h *UserHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if roleChanged {
+		h.auditLog.Log(audit.ActionRoleChange, currentUserID.(string), user.ID, c.ClientIP(), c.GetHeader("User-Agent"), map[string]any{
+			"from": previousRole,
+			"to":   user.Role,
+		})
+	}
+
 	c.JSON(http.StatusOK, user.ToResponse())
 }
 
@@ -226,11 +252,9 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	err := h.authService.ChangePassword(userID.(string), req.CurrentPassword, req.NewPassword)
+	err := h.authService.ChangePassword(userID.(string), req.NewPassword, c.GetHeader("User-Agent"), c.ClientIP())
 	if err != nil {
 		switch err {
-		case service.ErrInvalidCredentials:
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "current password is incorrect"})
 		case repository.ErrUserNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 		default:
@@ -246,6 +270,80 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 	c.JSON(http.StatusOK, model.MessageResponse{Message: "password changed successfully"})
 }
 
+// EnrollMFA starts TOTP enrollment and returns a provisioning secret
+// POST /v1/users/me/mfa/enroll
+func (h *UserHandler) EnrollMFA(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	resp, err := h.mfaService.EnrollTOTP(userID.(string))
+	if err != nil {
+		if err == service.ErrMFAAlreadyEnabled {
+			c.JSON(http.StatusConflict, gin.H{"error": "mfa already enabled"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enroll mfa"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ConfirmMFA confirms enrollment with a code from the authenticator app and
+// returns one-time recovery codes
+// POST /v1/users/me/mfa/confirm
+func (h *UserHandler) ConfirmMFA(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.mfaService.ConfirmTOTP(userID.(string), req.Code, c.GetHeader("User-Agent"), c.ClientIP())
+	if err != nil {
+		switch err {
+		case service.ErrInvalidCredentials:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid code"})
+		case service.ErrMFANotEnrolled:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "mfa not enrolled"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to confirm mfa"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DisableMFA disables TOTP for the current user. Requires a recent
+// reauthentication (see middleware.RequireRecentAuth).
+// DELETE /v1/users/me/mfa
+func (h *UserHandler) DisableMFA(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.mfaService.DisableTOTP(userID.(string)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to disable mfa"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.MessageResponse{Message: "mfa disabled successfully"})
+}
+
 // GetByUsername returns a user by their username
 // GET /v1/users/by-username/:username
 func (h *UserHandler) GetByUsername(c *gin.Context) {
@@ -275,6 +373,125 @@ func (h *UserHandler) GetByUsername(c *gin.Context) {
 	})
 }
 
+// CreateAccessToken mints a new personal access token for the current user.
+// The raw token is only ever returned in this response.
+// POST /v1/users/me/access-tokens
+func (h *UserHandler) CreateAccessToken(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req model.CreateAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, raw, err := h.accessTokenService.Create(userID.(string), req.Name, req.Scopes, req.ExpiresInDays, c.GetHeader("User-Agent"), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create access token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.AccessTokenSecretResponse{
+		AccessTokenResponse: token.ToResponse(),
+		Token:               raw,
+	})
+}
+
+// ListAccessTokens returns every personal access token owned by the current user.
+// GET /v1/users/me/access-tokens
+func (h *UserHandler) ListAccessTokens(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	tokens, err := h.accessTokenService.ListByOwner(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list access tokens"})
+		return
+	}
+
+	responses := make([]model.AccessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		responses = append(responses, token.ToResponse())
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// RevokeAccessToken revokes a personal access token owned by the current user.
+// DELETE /v1/users/me/access-tokens/:tokenId
+func (h *UserHandler) RevokeAccessToken(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	if err := h.accessTokenService.Revoke(c.Param("tokenId"), userID, c.GetHeader("User-Agent"), c.ClientIP()); err != nil {
+		if err == repository.ErrAccessTokenNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "access token not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke access token"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetMySessions lists the current user's active refresh-token sessions, so
+// they can spot and revoke a device they don't recognize.
+// GET /v1/users/me/sessions
+func (h *UserHandler) GetMySessions(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	sessions, err := h.authService.ListSessions(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list sessions"})
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession revokes a single session owned by the current user.
+// DELETE /v1/users/me/sessions/:sessionId
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	err := h.authService.RevokeSession(c.Param("sessionId"), userID, c.GetHeader("User-Agent"), c.ClientIP())
+	if err != nil {
+		if err == repository.ErrSessionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke session"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetMySecurityEvents returns the current user's own audit trail - logins,
+// failures, password changes - newest-first with cursor pagination, the
+// self-service counterpart to AuditHandler.List.
+// GET /v1/users/me/security/events
+func (h *UserHandler) GetMySecurityEvents(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+
+	filter := repository.AuditLogFilter{
+		ActorUserID: userID,
+		Cursor:      c.Query("cursor"),
+	}
+	if limit, err := strconv.Atoi(c.DefaultQuery("limit", "20")); err == nil {
+		filter.Limit = limit
+	}
+
+	entries, nextCursor, err := h.auditRepo.List(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch security events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": entries,
+		"meta": gin.H{
+			"nextCursor": nextCursor,
+		},
+	})
+}
+
 // GetByExternalID returns a user by their external ID
 // GET /v1/users/by-external-id/:externalId
 func (h *UserHandler) GetByExternalID(c *gin.Context) {
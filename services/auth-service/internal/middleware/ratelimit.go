@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/cache"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimiter is a fixed-window request counter, one window per key, backed
+// by the same cache.Cache used to front session/domain-access token lookups
+// (see cached_session_repository.go): counting through Redis once
+// CACHE_BACKEND is configured means the limit actually holds across
+// replicas, and either backend's TTL eviction keeps a key from outliving its
+// window instead of accumulating in memory forever.
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+	cache  cache.Cache
+}
+
+type rateWindow struct {
+	Count   int       `json:"count"`
+	ResetAt time.Time `json:"resetAt"`
+}
+
+// NewRateLimiter allows up to limit requests per window for each key passed
+// to Allow.
+func NewRateLimiter(limit int, window time.Duration, c cache.Cache) *RateLimiter {
+	return &RateLimiter{
+		limit:  limit,
+		window: window,
+		cache:  c,
+	}
+}
+
+// Allow increments key's counter and reports whether it's still within
+// limit. When it isn't, it also returns how long until the window resets.
+//
+// The read-modify-write against the cache isn't atomic, so two requests
+// landing in the same instant can both read the same count and both get
+// admitted - the same best-effort tradeoff every other cache.Cache consumer
+// in this service makes. That's an acceptable miss for a rate limit (worst
+// case a handful of extra requests squeak through) and strictly better than
+// a counter that never resets.
+func (rl *RateLimiter) Allow(key string) (allowed bool, retryAfter time.Duration) {
+	ctx := context.Background()
+	cacheKey := "ratelimit:" + key
+	now := time.Now()
+
+	w := rateWindow{ResetAt: now.Add(rl.window)}
+	if cached, found, err := rl.cache.Get(ctx, cacheKey); err == nil && found {
+		var existing rateWindow
+		if err := json.Unmarshal([]byte(cached), &existing); err == nil && now.Before(existing.ResetAt) {
+			w = existing
+		}
+	}
+
+	w.Count++
+	if encoded, err := json.Marshal(w); err == nil {
+		rl.cache.Set(ctx, cacheKey, string(encoded), time.Until(w.ResetAt))
+	}
+
+	if w.Count > rl.limit {
+		return false, time.Until(w.ResetAt)
+	}
+	return true, 0
+}
+
+// RateLimitByIP bounds each client IP to limit requests per window, mounted
+// on the brute-force-prone auth endpoints (login, register, refresh,
+// change-password).
+func RateLimitByIP(rl *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, retryAfter := rl.Allow(c.ClientIP())
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":      "too many requests",
+				"retryAfter": int64(retryAfter.Seconds()),
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AccessTokenPrefix marks the raw token value as a personal access token
+// rather than a signed JWT, so AuthMiddleware can tell the two apart without
+// attempting (and failing) a JWT parse first.
+const AccessTokenPrefix = "mgk_"
+
+// AccessToken is a long-lived, user-issued bearer credential for scripts and
+// third-party tools that can't carry out the interactive login flow. Unlike
+// a session JWT it isn't signed or time-boxed to minutes: it's looked up by
+// TokenHash on every request, so it can be revoked immediately and its use
+// tracked via LastUsedAt.
+type AccessToken struct {
+	ID     string `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	UserID string `gorm:"column:user_id;type:varchar(36);index;not null" json:"userId"`
+	Name   string `gorm:"type:varchar(100);not null" json:"name"`
+	// TokenHash is the hex-encoded SHA-256 of the raw token; only the raw
+	// value is prefixed/returned to the caller, and only this once, at
+	// creation (see AccessTokenSecretResponse).
+	TokenHash string `gorm:"column:token_hash;uniqueIndex;type:varchar(64);not null" json:"-"`
+	// Scopes gates what the token can do, parsed with package scope the same
+	// way as a DomainAccessToken's.
+	Scopes     []string   `gorm:"type:jsonb;serializer:json" json:"scopes,omitempty"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at" json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at" json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `gorm:"column:created_at" json:"createdAt"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (t *AccessToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsExpired reports whether the token has a deadline and it has passed.
+func (t *AccessToken) IsExpired() bool {
+	return t.ExpiresAt != nil && time.Now().After(*t.ExpiresAt)
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditLog records a single authentication-relevant event for forensic
+// review. ActorUserID is who performed the action (empty for
+// unauthenticated attempts such as a failed login); TargetUserID is who it
+// affected, which is usually the same user but differs for admin actions
+// like a role change.
+type AuditLog struct {
+	ID           string         `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	ActorUserID  string         `gorm:"column:actor_user_id;type:varchar(36);index" json:"actorUserId"`
+	TargetUserID string         `gorm:"column:target_user_id;type:varchar(36);index" json:"targetUserId"`
+	Action       string         `gorm:"type:varchar(50);index;not null" json:"action"`
+	IP           string         `gorm:"type:varchar(45)" json:"ip"`
+	UserAgent    string         `gorm:"column:user_agent;type:varchar(500)" json:"userAgent"`
+	Metadata     map[string]any `gorm:"type:jsonb;serializer:json" json:"metadata,omitempty"`
+	CreatedAt    time.Time      `gorm:"column:created_at;index" json:"createdAt"`
+
+	// PrevHash/Hash form a tamper-evident chain (see audit.Logger.chainHash):
+	// Hash covers this row's fields plus PrevHash, which is the previous
+	// row's Hash, so editing or deleting any persisted row invalidates every
+	// Hash after it.
+	PrevHash string `gorm:"column:prev_hash;type:varchar(64)" json:"prevHash"`
+	Hash     string `gorm:"column:hash;type:varchar(64);index" json:"hash"`
+}
+
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuthorizationCode is a short-lived code issued at /v1/oauth/authorize and
+// redeemed once at /v1/oauth/token (authorization_code + PKCE grant).
+type AuthorizationCode struct {
+	ID                  string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	Code                string    `gorm:"uniqueIndex;type:varchar(128);not null" json:"-"`
+	ClientID            string    `gorm:"column:client_id;type:varchar(64);index;not null" json:"clientId"`
+	UserID              string    `gorm:"column:user_id;type:varchar(36);index;not null" json:"userId"`
+	RedirectURI         string    `gorm:"column:redirect_uri;type:varchar(500);not null" json:"redirectUri"`
+	Scope               string    `gorm:"type:text" json:"scope"`
+	Nonce               string    `gorm:"type:varchar(255)" json:"-"`
+	CodeChallenge       string    `gorm:"column:code_challenge;type:varchar(255)" json:"-"`
+	CodeChallengeMethod string    `gorm:"column:code_challenge_method;type:varchar(10)" json:"-"`
+	Used                bool      `gorm:"default:false" json:"-"`
+	ExpiresAt           time.Time `gorm:"column:expires_at" json:"expiresAt"`
+	CreatedAt           time.Time `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (c *AuthorizationCode) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// AccessGrant tracks an issued OAuth access/refresh token pair so it can be
+// looked up for introspection and revoked independently of the JWT itself.
+type AccessGrant struct {
+	ID               string     `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	ClientID         string     `gorm:"column:client_id;type:varchar(64);index;not null" json:"clientId"`
+	UserID           string     `gorm:"column:user_id;type:varchar(36);index" json:"userId"`
+	Scope            string     `gorm:"type:text" json:"scope"`
+	RefreshTokenHash string     `gorm:"column:refresh_token_hash;type:varchar(255);index" json:"-"`
+	ExpiresAt        time.Time  `gorm:"column:expires_at" json:"expiresAt"`
+	RevokedAt        *time.Time `gorm:"column:revoked_at" json:"revokedAt"`
+	CreatedAt        time.Time  `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (g *AccessGrant) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == "" {
+		g.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// SigningKey is an RS256 keypair used to sign OIDC ID tokens and access
+// tokens, published (public half only) at /.well-known/jwks.json.
+type SigningKey struct {
+	ID         string     `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	Kid        string     `gorm:"uniqueIndex;type:varchar(64);not null" json:"kid"`
+	Algorithm  string     `gorm:"type:varchar(20);not null" json:"alg"`
+	PrivateKey string     `gorm:"column:private_key;type:text;not null" json:"-"`
+	PublicKey  string     `gorm:"column:public_key;type:text;not null" json:"-"`
+	Status     string     `gorm:"type:varchar(20);default:'active'" json:"status"` // active | retired
+	NotBefore  time.Time  `gorm:"column:not_before" json:"notBefore"`
+	NotAfter   *time.Time `gorm:"column:not_after" json:"notAfter"`
+	CreatedAt  time.Time  `gorm:"column:created_at" json:"createdAt"`
+}
+
+func (k *SigningKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == "" {
+		k.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -13,6 +13,9 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	// Provider selects which registered service.LoginProvider handles this
+	// attempt ("local", "ldap"); empty defaults to "local". Case-insensitive.
+	Provider string `json:"provider,omitempty"`
 }
 
 type RefreshTokenRequest struct {
@@ -23,23 +26,42 @@ type UpdateUserRequest struct {
 	Username *string `json:"username" binding:"omitempty,min=3,max=50"`
 	Name     *string `json:"name" binding:"omitempty,max=100"`
 	Picture  *string `json:"picture"`
+	// Role is only honored when the caller is a MANAGER/MASTER updating
+	// someone else's account; see UserHandler.Update.
+	Role *Role `json:"role" binding:"omitempty,oneof=GUEST USER MANAGER MASTER"`
 }
 
+// ChangePasswordRequest no longer carries CurrentPassword: the handler is
+// gated behind middleware.RequireRecentAuth, which already requires a fresh
+// login or step_up token before this is reached.
 type ChangePasswordRequest struct {
-	CurrentPassword string `json:"currentPassword" binding:"required"`
-	NewPassword     string `json:"newPassword" binding:"required,min=8"`
+	NewPassword string `json:"newPassword" binding:"required,min=8"`
 }
 
 type GrantDomainAccessRequest struct {
 	Domain         string `json:"domain" binding:"required"`
 	ExpiresInHours int    `json:"expiresInHours" binding:"required,min=1,max=72"`
 	RecipientEmail string `json:"recipientEmail" binding:"omitempty,email"`
+	// Scopes is space-delimited per RFC 6749 section 3.3, e.g.
+	// "girok:read girok:write:notes". Empty keeps the legacy all-or-nothing
+	// grant.
+	Scopes string `json:"scopes" binding:"omitempty"`
 }
 
 type ToggleProviderRequest struct {
 	Enabled bool `json:"enabled"`
 }
 
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfaToken" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type ReauthenticateRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
 // Response DTOs
 type AuthResponse struct {
 	User         UserResponse `json:"user"`
@@ -72,6 +94,7 @@ type DomainAccessResponse struct {
 	AccessToken string    `json:"accessToken"`
 	ExpiresAt   time.Time `json:"expiresAt"`
 	AccessURL   string    `json:"accessUrl"`
+	Scopes      []string  `json:"scopes,omitempty"`
 }
 
 type OAuthProviderStatusResponse struct {
@@ -79,13 +102,15 @@ type OAuthProviderStatusResponse struct {
 	Enabled  bool         `json:"enabled"`
 }
 
-type OAuthProviderConfigResponse struct {
+type AuthProviderConfigResponse struct {
 	ID          string       `json:"id"`
 	Provider    AuthProvider `json:"provider"`
 	Enabled     bool         `json:"enabled"`
 	DisplayName string       `json:"displayName"`
 	Description string       `json:"description"`
 	CallbackURL string       `json:"callbackUrl"`
+	IssuerURL   string       `json:"issuerUrl"`
+	Scopes      string       `json:"scopes"`
 	UpdatedAt   string       `json:"updatedAt"`
 	UpdatedBy   string       `json:"updatedBy"`
 }
@@ -94,6 +119,69 @@ type MessageResponse struct {
 	Message string `json:"message"`
 }
 
+type CreateAccessTokenRequest struct {
+	Name string `json:"name" binding:"required"`
+	// Scopes e.g. ["read:profile", "write:profile", "admin:users"].
+	Scopes []string `json:"scopes"`
+	// ExpiresInDays is optional; omitted/zero means the token never expires.
+	ExpiresInDays int `json:"expiresInDays" binding:"omitempty,min=1"`
+}
+
+// AccessTokenResponse never includes the token hash or raw value; the raw
+// value is only returned once, at creation, via AccessTokenSecretResponse.
+type AccessTokenResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  string     `json:"createdAt"`
+}
+
+type AccessTokenSecretResponse struct {
+	AccessTokenResponse
+	Token string `json:"token"`
+}
+
+// ToResponse converts an AccessToken to its public (hash-free) representation.
+func (t *AccessToken) ToResponse() AccessTokenResponse {
+	return AccessTokenResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Scopes:     t.Scopes,
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// MFAChallengeResponse is returned from Login instead of AuthResponse when
+// the account has TOTP enabled; the caller must exchange MFAToken + a valid
+// code at POST /v1/auth/mfa/verify for real tokens.
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfaRequired"`
+	MFAToken    string `json:"mfaToken"`
+	ExpiresIn   int64  `json:"expiresIn"`
+}
+
+// MFAEnrollResponse carries the provisioning secret for authenticator apps.
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpAuthUrl"`
+}
+
+// MFAConfirmResponse is returned once enrollment is confirmed with a valid code.
+type MFAConfirmResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// StepUpResponse carries a short-lived token proving the user recently
+// reauthenticated, required before sensitive operations.
+type StepUpResponse struct {
+	StepUpToken string `json:"stepUpToken"`
+	ExpiresIn   int64  `json:"expiresIn"`
+}
+
 // Convert User to UserResponse
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
@@ -111,15 +199,49 @@ func (u *User) ToResponse() UserResponse {
 	}
 }
 
-// Convert OAuthProviderConfig to response
-func (o *OAuthProviderConfig) ToResponse() OAuthProviderConfigResponse {
-	return OAuthProviderConfigResponse{
+// OAuthClientResponse never includes the client secret; it's only returned
+// once, at creation/regeneration time, via OAuthClientSecretResponse.
+type OAuthClientResponse struct {
+	ID           string `json:"id"`
+	ClientID     string `json:"clientId"`
+	Name         string `json:"name"`
+	RedirectURIs string `json:"redirectUris"`
+	Scopes       string `json:"scopes"`
+	GrantTypes   string `json:"grantTypes"`
+	Confidential bool   `json:"confidential"`
+	CreatedAt    string `json:"createdAt"`
+}
+
+type OAuthClientSecretResponse struct {
+	OAuthClientResponse
+	ClientSecret string `json:"clientSecret"`
+}
+
+// ToResponse converts an OAuthClient to its public (secret-free) representation.
+func (c *OAuthClient) ToResponse() OAuthClientResponse {
+	return OAuthClientResponse{
+		ID:           c.ID,
+		ClientID:     c.ClientID,
+		Name:         c.Name,
+		RedirectURIs: c.RedirectURIs,
+		Scopes:       c.Scopes,
+		GrantTypes:   c.GrantTypes,
+		Confidential: c.Confidential,
+		CreatedAt:    c.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// Convert AuthProviderConfig to response
+func (o *AuthProviderConfig) ToResponse() AuthProviderConfigResponse {
+	return AuthProviderConfigResponse{
 		ID:          o.ID,
 		Provider:    o.Provider,
 		Enabled:     o.Enabled,
 		DisplayName: o.DisplayName,
 		Description: o.Description,
 		CallbackURL: o.CallbackURL,
+		IssuerURL:   o.IssuerURL,
+		Scopes:      o.Scopes,
 		UpdatedAt:   o.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedBy:   o.UpdatedBy,
 	}
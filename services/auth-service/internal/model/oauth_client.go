@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OAuthClient is a registered first- or third-party application allowed to
+// use my-girok as an OIDC provider ("Login with my-girok").
+type OAuthClient struct {
+	ID               string `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	ClientID         string `gorm:"column:client_id;uniqueIndex;type:varchar(64);not null" json:"clientId"`
+	ClientSecretHash string `gorm:"column:client_secret_hash;type:varchar(255)" json:"-"`
+	Name             string `gorm:"type:varchar(100);not null" json:"name"`
+	// RedirectURIs and Scopes/GrantTypes are stored as space-delimited
+	// strings, mirroring how scopes travel on the wire per RFC 6749.
+	RedirectURIs string `gorm:"column:redirect_uris;type:text" json:"redirectUris"`
+	Scopes       string `gorm:"type:text" json:"scopes"`
+	GrantTypes   string `gorm:"column:grant_types;type:text" json:"grantTypes"`
+	Confidential bool   `gorm:"default:true" json:"confidential"`
+	OwnerUserID  string `gorm:"column:owner_user_id;type:varchar(36);index" json:"ownerUserId"`
+
+	CreatedAt time.Time `gorm:"column:created_at" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updatedAt"`
+}
+
+func (c *OAuthClient) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
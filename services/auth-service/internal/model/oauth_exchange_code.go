@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OAuthExchangeCode is a one-time code handed to the browser at the end of
+// an upstream OAuth callback instead of the access/refresh tokens
+// themselves, so the tokens never appear in a URL (browser history,
+// Referer header, proxy access logs). The frontend immediately exchanges it
+// for the real tokens at POST /v1/auth/oauth/exchange; Consume deletes the
+// row, so the code is single-use and short-lived.
+type OAuthExchangeCode struct {
+	ID           string    `gorm:"primaryKey;type:varchar(36)" json:"-"`
+	Code         string    `gorm:"uniqueIndex;type:varchar(64);not null" json:"-"`
+	AccessToken  string    `gorm:"column:access_token;type:text;not null" json:"-"`
+	RefreshToken string    `gorm:"column:refresh_token;type:text;not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"column:expires_at" json:"-"`
+	CreatedAt    time.Time `gorm:"column:created_at" json:"-"`
+}
+
+func (c *OAuthExchangeCode) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
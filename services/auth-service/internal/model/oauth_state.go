@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OAuthState is a server-side record of an in-flight upstream OAuth
+// authorization request, replacing the old cookie-only state. Consuming it
+// (see repository.OAuthStateRepository.Consume) deletes the row, so a state
+// value can only be redeemed once and survives independently of the
+// browser's cookie jar.
+//
+// This stays Postgres-backed rather than Redis-backed: it already gets
+// atomic consume-and-delete and works across every instance behind the
+// load balancer, which is the property that matters here. A shared Redis
+// cache is being introduced separately for session/token lookups, where the
+// access pattern is read-heavy instead of one-time-use.
+type OAuthState struct {
+	ID       string       `gorm:"primaryKey;type:varchar(36)" json:"-"`
+	Provider AuthProvider `gorm:"type:varchar(20);index;not null" json:"-"`
+	State    string       `gorm:"uniqueIndex;type:varchar(64);not null" json:"-"`
+	// CodeVerifier is the PKCE (RFC 7636) code verifier generated for this
+	// login; its S256 challenge is sent on the authorization request, and
+	// the verifier itself is sent back on the token exchange, so a stolen
+	// authorization code is useless without it.
+	CodeVerifier string    `gorm:"column:code_verifier;type:varchar(128);not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"column:expires_at" json:"-"`
+	CreatedAt    time.Time `gorm:"column:created_at" json:"-"`
+}
+
+func (s *OAuthState) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -24,24 +24,35 @@ const (
 	ProviderKakao  AuthProvider = "KAKAO"
 	ProviderNaver  AuthProvider = "NAVER"
 	ProviderApple  AuthProvider = "APPLE"
+	ProviderGitHub AuthProvider = "GITHUB"
+	ProviderLDAP   AuthProvider = "LDAP"
 )
 
 type User struct {
-	ID             string       `gorm:"primaryKey;type:varchar(36)" json:"id"`
-	ExternalID     string       `gorm:"uniqueIndex;type:varchar(10);not null" json:"externalId"` // 10-char time-based ID for external partners
-	Email          string       `gorm:"uniqueIndex;type:varchar(255);not null" json:"email"`
-	Username       string       `gorm:"uniqueIndex;type:varchar(100)" json:"username"`
-	PasswordHash   string       `gorm:"type:varchar(255)" json:"-"`
-	Role           Role         `gorm:"type:varchar(20);default:'USER'" json:"role"`
-	Provider       AuthProvider `gorm:"type:varchar(20);default:'LOCAL'" json:"provider"`
-	ProviderID     string       `gorm:"column:provider_id;type:varchar(255);index" json:"-"` // OAuth provider user ID
-	Name           string       `gorm:"type:varchar(100)" json:"name"`
-	Picture        string       `gorm:"type:text" json:"picture"`
-	EmailVerified  bool         `gorm:"column:email_verified;default:false" json:"emailVerified"`
-	IsActive       bool         `gorm:"default:true" json:"isActive"`
-	LastLoginAt    *time.Time   `gorm:"column:last_login_at" json:"lastLoginAt"`
-	CreatedAt      time.Time    `gorm:"column:created_at" json:"createdAt"`
-	UpdatedAt      time.Time    `gorm:"column:updated_at" json:"updatedAt"`
+	ID            string       `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	ExternalID    string       `gorm:"uniqueIndex;type:varchar(10);not null" json:"externalId"` // 10-char time-based ID for external partners
+	Email         string       `gorm:"uniqueIndex;type:varchar(255);not null" json:"email"`
+	Username      string       `gorm:"uniqueIndex;type:varchar(100)" json:"username"`
+	PasswordHash  string       `gorm:"type:varchar(255)" json:"-"`
+	Role          Role         `gorm:"type:varchar(20);default:'USER'" json:"role"`
+	Provider      AuthProvider `gorm:"type:varchar(20);default:'LOCAL'" json:"provider"`
+	ProviderID    string       `gorm:"column:provider_id;type:varchar(255);index" json:"-"` // OAuth provider user ID
+	Name          string       `gorm:"type:varchar(100)" json:"name"`
+	Picture       string       `gorm:"type:text" json:"picture"`
+	EmailVerified bool         `gorm:"column:email_verified;default:false" json:"emailVerified"`
+	IsActive      bool         `gorm:"default:true" json:"isActive"`
+	LastLoginAt   *time.Time   `gorm:"column:last_login_at" json:"lastLoginAt"`
+
+	// TOTPSecret is envelope-encrypted at rest (see internal/crypto/kms,
+	// same serializer AuthProviderConfig.ClientSecret uses); never exposed
+	// via JSON.
+	TOTPSecret  string `gorm:"column:totp_secret;type:text;serializer:envelope" json:"-"`
+	TOTPEnabled bool   `gorm:"column:totp_enabled;default:false" json:"totpEnabled"`
+	// RecoveryCodes stores a JSON array of bcrypt-hashed, single-use codes.
+	RecoveryCodes string `gorm:"column:recovery_codes;type:text" json:"-"`
+
+	CreatedAt time.Time `gorm:"column:created_at" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updatedAt"`
 
 	Sessions     []Session           `gorm:"foreignKey:UserID" json:"-"`
 	DomainAccess []DomainAccessToken `gorm:"foreignKey:UserID" json:"-"`
@@ -54,15 +65,24 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// Session tracks one refresh-token family per login. Only the current
+// token's hash is stored (never the token itself); TokenFamilyID is stable
+// across rotations so a presented token that doesn't match CurrentTokenHash
+// can be recognized as replay of an already-rotated token and the whole
+// family revoked (OAuth 2.1 section 4.14 reuse detection). CurrentTokenHash
+// is intentionally not wrapped in internal/crypto/kms envelope encryption:
+// it's already one-way (SHA-256), so there's no plaintext secret at rest
+// left to protect that the hash doesn't already cover.
 type Session struct {
-	ID           string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
-	UserID       string    `gorm:"type:varchar(36);index;not null" json:"userId"`
-	RefreshToken string    `gorm:"type:text;not null" json:"-"`
-	UserAgent    string    `gorm:"type:varchar(500)" json:"userAgent"`
-	IP           string    `gorm:"type:varchar(45)" json:"ip"`
-	ExpiresAt    time.Time `json:"expiresAt"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	ID               string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	UserID           string    `gorm:"type:varchar(36);index;not null" json:"userId"`
+	TokenFamilyID    string    `gorm:"column:token_family_id;type:varchar(36);uniqueIndex;not null" json:"-"`
+	CurrentTokenHash string    `gorm:"column:current_token_hash;type:varchar(64);not null" json:"-"`
+	UserAgent        string    `gorm:"type:varchar(500)" json:"userAgent"`
+	IP               string    `gorm:"type:varchar(45)" json:"ip"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
 
 	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
 }
@@ -71,6 +91,9 @@ func (s *Session) BeforeCreate(tx *gorm.DB) error {
 	if s.ID == "" {
 		s.ID = uuid.New().String()
 	}
+	if s.TokenFamilyID == "" {
+		s.TokenFamilyID = uuid.New().String()
+	}
 	return nil
 }
 
@@ -79,7 +102,20 @@ type DomainAccessToken struct {
 	ID        string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
 	UserID    string    `gorm:"column:user_id;type:varchar(36);index;not null" json:"userId"`
 	Domain    string    `gorm:"type:varchar(100);index;not null" json:"domain"` // e.g., 'resume', 'portfolio'
-	Token     string    `gorm:"uniqueIndex;type:text;not null" json:"-"`
+	// Token is intentionally stored as plaintext, not envelope-encrypted
+	// (see internal/crypto/kms and Session.CurrentTokenHash above): it's
+	// looked up with an exact-match WHERE clause in
+	// DomainAccessRepository.FindByToken, which an encrypted column can't
+	// support, and unlike a refresh token it must also be echoed back to
+	// the granter verbatim for the one-time share link - so it can't be
+	// reduced to a one-way hash either. It keeps the same random-128-bit,
+	// short-TTL, revocable shape as every other bearer token this service
+	// issues, which is its actual defense.
+	Token string `gorm:"uniqueIndex;type:text;not null" json:"-"`
+	// Scopes limits what the recipient of this link can do with it, e.g.
+	// ["girok:read", "girok:write:notes"]; parsed with package scope. Empty
+	// means the legacy all-or-nothing grant (full read access to the domain).
+	Scopes    []string  `gorm:"type:jsonb;serializer:json" json:"scopes,omitempty"`
 	ExpiresAt time.Time `gorm:"column:expires_at" json:"expiresAt"`
 	CreatedAt time.Time `gorm:"column:created_at" json:"createdAt"`
 
@@ -93,21 +129,46 @@ func (d *DomainAccessToken) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-// OAuthProviderConfig for managing OAuth provider settings
-type OAuthProviderConfig struct {
-	ID           string       `gorm:"primaryKey;type:varchar(36)" json:"id"`
-	Provider     AuthProvider `gorm:"type:varchar(20);uniqueIndex;not null" json:"provider"`
-	Enabled      bool         `gorm:"default:true" json:"enabled"`
-	DisplayName  string       `gorm:"column:display_name;type:varchar(100)" json:"displayName"`
-	Description  string       `gorm:"type:text" json:"description"`
-	ClientID     string       `gorm:"column:client_id;type:varchar(255)" json:"-"`
-	ClientSecret string       `gorm:"column:client_secret;type:varchar(255)" json:"-"`
-	CallbackURL  string       `gorm:"column:callback_url;type:varchar(500)" json:"callbackUrl"`
-	UpdatedAt    time.Time    `gorm:"column:updated_at" json:"updatedAt"`
-	UpdatedBy    string       `gorm:"column:updated_by;type:varchar(36)" json:"updatedBy"`
+// AuthProviderConfig for managing OAuth provider settings. Provider is no
+// longer limited to the built-in enum values: any row here with Enabled=true
+// and an IssuerURL is discovered and wired up as a login option at request
+// time, so adding a new upstream IdP is a DB row, not a code change.
+type AuthProviderConfig struct {
+	ID          string       `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	Provider    AuthProvider `gorm:"type:varchar(20);uniqueIndex;not null" json:"provider"`
+	Enabled     bool         `gorm:"default:true" json:"enabled"`
+	DisplayName string       `gorm:"column:display_name;type:varchar(100)" json:"displayName"`
+	Description string       `gorm:"type:text" json:"description"`
+
+	ClientID string `gorm:"column:client_id;type:varchar(255)" json:"-"`
+	// ClientSecret is envelope-encrypted at rest (see internal/crypto/kms):
+	// the serializer tag transparently decrypts it back to plaintext on
+	// load, so callers like oauthupstream never see ciphertext.
+	ClientSecret string `gorm:"column:client_secret;type:text;serializer:envelope" json:"-"`
+	CallbackURL  string `gorm:"column:callback_url;type:varchar(500)" json:"callbackUrl"`
+
+	// IssuerURL is the OIDC issuer; its well-known discovery document is
+	// fetched on first use and cached until UpdatedAt changes.
+	IssuerURL string `gorm:"column:issuer_url;type:varchar(500)" json:"issuerUrl"`
+	// Scopes is space-delimited, mirroring OAuthClient.Scopes.
+	Scopes string `gorm:"type:varchar(255)" json:"scopes"`
+	// UserInfoMapping is a JSON object of canonical claim name ("email",
+	// "name", "picture") to a list of alias keys to try in the userinfo
+	// response, e.g. {"picture":["picture","avatar_url"]}. Canonical claims
+	// left unmapped fall back to a built-in default alias list.
+	UserInfoMapping string `gorm:"column:user_info_mapping;type:text" json:"userInfoMapping"`
+	// RoleMapping is a JSON object of upstream group/role claim value to
+	// the local model.Role it grants, e.g. {"girok-admins":"MASTER"}. The
+	// upstream value is read from the userinfo "groups"/"roles" claim (or
+	// UserInfoMapping's override of "groups"); unmatched groups are ignored
+	// and a user with no match at all keeps/gets the default RoleUser.
+	RoleMapping string `gorm:"column:role_mapping;type:text" json:"roleMapping"`
+
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updatedAt"`
+	UpdatedBy string    `gorm:"column:updated_by;type:varchar(36)" json:"updatedBy"`
 }
 
-func (o *OAuthProviderConfig) BeforeCreate(tx *gorm.DB) error {
+func (o *AuthProviderConfig) BeforeCreate(tx *gorm.DB) error {
 	if o.ID == "" {
 		o.ID = uuid.New().String()
 	}
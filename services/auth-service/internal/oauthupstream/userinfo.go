@@ -0,0 +1,130 @@
+package oauthupstream
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultAliases lists the userinfo claim keys tried for each canonical
+// field when a provider's UserInfoMapping doesn't override it. Covers the
+// common spellings across Google, Kakao, Naver-shaped and plain OIDC
+// userinfo responses.
+var defaultAliases = map[string][]string{
+	"id":      {"sub", "id"},
+	"email":   {"email"},
+	"name":    {"name", "display_name", "nickname"},
+	"picture": {"picture", "avatar_url", "profile_image"},
+	"groups":  {"groups", "roles"},
+}
+
+// UserInfoFields wraps a decoded userinfo JSON response so callers can pull
+// canonical fields out of however a given IdP happens to have named them.
+type UserInfoFields map[string]interface{}
+
+// GetStringFromKeysOrEmpty returns the first non-empty string value found
+// under any of keys, or "" if none are present or none hold a string/number.
+func (f UserInfoFields) GetStringFromKeysOrEmpty(keys ...string) string {
+	for _, key := range keys {
+		v, ok := f[key]
+		if !ok || v == nil {
+			continue
+		}
+		switch val := v.(type) {
+		case string:
+			if val != "" {
+				return val
+			}
+		case float64:
+			return fmt.Sprintf("%v", val)
+		}
+	}
+	return ""
+}
+
+// Resolve looks up canonical (one of "id", "email", "name", "picture"),
+// trying the provider's custom mapping first and falling back to
+// defaultAliases.
+func (f UserInfoFields) Resolve(canonical string, mapping map[string][]string) string {
+	if keys, ok := mapping[canonical]; ok && len(keys) > 0 {
+		return f.GetStringFromKeysOrEmpty(keys...)
+	}
+	return f.GetStringFromKeysOrEmpty(defaultAliases[canonical]...)
+}
+
+// getStringSliceFromKeysOrEmpty returns the first key whose value is a
+// string or a list of strings, flattened into a slice. Most IdPs send
+// "groups"/"roles" as a JSON array; a few send a single space-delimited
+// string, which this also accepts.
+func (f UserInfoFields) getStringSliceFromKeysOrEmpty(keys ...string) []string {
+	for _, key := range keys {
+		v, ok := f[key]
+		if !ok || v == nil {
+			continue
+		}
+		switch val := v.(type) {
+		case []interface{}:
+			groups := make([]string, 0, len(val))
+			for _, item := range val {
+				if s, ok := item.(string); ok && s != "" {
+					groups = append(groups, s)
+				}
+			}
+			if len(groups) > 0 {
+				return groups
+			}
+		case string:
+			if val != "" {
+				return strings.Fields(val)
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveGroups reads the upstream group/role claim ("groups" canonical,
+// trying the provider's custom mapping first and falling back to
+// defaultAliases) for ResolveRole to match against a RoleMapping.
+func (f UserInfoFields) ResolveGroups(mapping map[string][]string) []string {
+	if keys, ok := mapping["groups"]; ok && len(keys) > 0 {
+		return f.getStringSliceFromKeysOrEmpty(keys...)
+	}
+	return f.getStringSliceFromKeysOrEmpty(defaultAliases["groups"]...)
+}
+
+// EmailVerified reports whether the userinfo response's "email_verified"
+// claim is truthy. Most OIDC-compliant providers send a JSON bool; a few
+// send the string "true". Anything else (including the claim being absent
+// entirely) is treated as unverified - callers gate account-linking by
+// email match on this, so the safe default is "no".
+func (f UserInfoFields) EmailVerified() bool {
+	v, ok := f["email_verified"]
+	if !ok {
+		return false
+	}
+	switch val := v.(type) {
+	case bool:
+		return val
+	case string:
+		return val == "true"
+	default:
+		return false
+	}
+}
+
+// ResolveRole maps groups through mapping and returns the highest-ranked
+// matching role ("MASTER" > "MANAGER" > "USER"), or "" if nothing matched -
+// callers should leave the user's existing role untouched in that case.
+func ResolveRole(groups []string, mapping map[string]string) string {
+	rank := map[string]int{"USER": 1, "MANAGER": 2, "MASTER": 3}
+	best := ""
+	for _, g := range groups {
+		role, ok := mapping[g]
+		if !ok {
+			continue
+		}
+		if best == "" || rank[role] > rank[best] {
+			best = role
+		}
+	}
+	return best
+}
@@ -0,0 +1,202 @@
+package oidc
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Authorize handles GET /v1/oauth/authorize. The caller must already be
+// authenticated (AuthMiddleware) so we know which user is granting consent.
+func (h *Handler) Authorize(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return
+	}
+
+	req := AuthorizeRequest{
+		ClientID:            c.Query("client_id"),
+		RedirectURI:         c.Query("redirect_uri"),
+		Scope:               c.Query("scope"),
+		State:               c.Query("state"),
+		Nonce:               c.Query("nonce"),
+		CodeChallenge:       c.Query("code_challenge"),
+		CodeChallengeMethod: c.Query("code_challenge_method"),
+		UserID:              userID.(string),
+	}
+
+	ac, err := h.service.Authorize(req)
+	if err != nil {
+		switch err {
+		case ErrInvalidClient, ErrInvalidRedirectURI:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to authorize"})
+		}
+		return
+	}
+
+	redirectURL := ac.RedirectURI + "?code=" + ac.Code
+	if req.State != "" {
+		redirectURL += "&state=" + req.State
+	}
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+// Token handles POST /v1/oauth/token, the single endpoint for all three
+// supported grant types (RFC 6749 section 4).
+func (h *Handler) Token(c *gin.Context) {
+	grantType := c.PostForm("grant_type")
+	params := map[string]string{
+		"client_id":     c.PostForm("client_id"),
+		"client_secret": c.PostForm("client_secret"),
+		"code":          c.PostForm("code"),
+		"redirect_uri":  c.PostForm("redirect_uri"),
+		"code_verifier": c.PostForm("code_verifier"),
+		"refresh_token": c.PostForm("refresh_token"),
+	}
+
+	resp, err := h.service.Token(grantType, params)
+	if err != nil {
+		switch err {
+		case ErrInvalidClient, ErrUnauthorizedClient:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case ErrInvalidGrant, ErrInvalidPKCE, ErrUnsupportedGrant:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue token"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// UserInfo handles GET /v1/oauth/userinfo (OpenID Connect Core section 5.3).
+func (h *Handler) UserInfo(c *gin.Context) {
+	token := bearerToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+		return
+	}
+
+	info, err := h.service.UserInfo(token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// Revoke handles POST /v1/oauth/revoke (RFC 7009).
+func (h *Handler) Revoke(c *gin.Context) {
+	token := c.PostForm("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	// RFC 7009 section 2.2: the endpoint returns 200 even if the token was
+	// already invalid, so clients can't probe for token validity.
+	_ = h.service.Revoke(token, c.GetHeader("User-Agent"), c.ClientIP())
+	c.Status(http.StatusOK)
+}
+
+// Introspect handles POST /v1/oauth/introspect (RFC 7662). It's intended
+// for resource servers, not end users, so an unparseable token is reported
+// as {"active": false} rather than an error - but per RFC 7662 section 2.1,
+// the caller itself must first authenticate as a registered OAuthClient
+// (HTTP Basic, same credentials the token endpoint accepts), otherwise
+// anyone could use this endpoint to learn the sub/scope/client_id behind an
+// arbitrary bearer token.
+func (h *Handler) Introspect(c *gin.Context) {
+	token := c.PostForm("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	clientID, clientSecret, ok := c.Request.BasicAuth()
+	if !ok {
+		clientID, clientSecret = c.PostForm("client_id"), c.PostForm("client_secret")
+	}
+	if _, err := h.service.authenticateClient(clientID, clientSecret); err != nil {
+		c.JSON(http.StatusOK, &IntrospectResponse{Active: false})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.service.Introspect(token))
+}
+
+// AuthorizedApps handles GET /v1/oauth/authorized-apps, listing the
+// third-party clients the caller has granted access to.
+func (h *Handler) AuthorizedApps(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	apps, err := h.service.AuthorizedApps(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list authorized apps"})
+		return
+	}
+	c.JSON(http.StatusOK, apps)
+}
+
+// Deauthorize handles DELETE /v1/oauth/authorized-apps/:clientId, revoking
+// every token the caller has granted that client.
+func (h *Handler) Deauthorize(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	clientID := c.Param("clientId")
+	if err := h.service.Deauthorize(userID, clientID, c.GetHeader("User-Agent"), c.ClientIP()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to deauthorize app"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "app deauthorized"})
+}
+
+// JWKS handles GET /.well-known/jwks.json.
+func (h *Handler) JWKS(c *gin.Context) {
+	jwks, err := h.service.keys.JWKS()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load signing keys"})
+		return
+	}
+	c.JSON(http.StatusOK, jwks)
+}
+
+// Discovery handles GET /.well-known/openid-configuration (OIDC Discovery 1.0).
+func (h *Handler) Discovery(c *gin.Context) {
+	issuer := h.service.issuer()
+	c.JSON(http.StatusOK, gin.H{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/v1/oauth/authorize",
+		"token_endpoint":                        issuer + "/v1/oauth/token",
+		"userinfo_endpoint":                     issuer + "/v1/oauth/userinfo",
+		"revocation_endpoint":                   issuer + "/v1/oauth/revoke",
+		"introspection_endpoint":                issuer + "/v1/oauth/introspect",
+		"jwks_uri":                              issuer + "/.well-known/jwks.json",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"grant_types_supported":                 []string{"authorization_code", "refresh_token", "client_credentials"},
+		"code_challenge_methods_supported":      []string{"S256", "plain"},
+		"scopes_supported":                      []string{"openid", "profile", "email"},
+	})
+}
+
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	const prefix = "Bearer "
+	if len(header) > len(prefix) && header[:len(prefix)] == prefix {
+		return header[len(prefix):]
+	}
+	return ""
+}
@@ -0,0 +1,171 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/repository"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/utils"
+)
+
+const rsaKeySize = 2048
+
+// KeyManager owns the RS256 signing keys used for ID tokens and access
+// tokens. Keys are generated lazily and persisted so every instance of the
+// service signs with (and can verify) the same key set.
+type KeyManager struct {
+	repo *repository.SigningKeyRepository
+}
+
+func NewKeyManager(repo *repository.SigningKeyRepository) *KeyManager {
+	return &KeyManager{repo: repo}
+}
+
+// ActiveKey returns the current signing key, generating one on first use.
+func (m *KeyManager) ActiveKey() (*rsa.PrivateKey, string, error) {
+	key, err := m.repo.FindActive()
+	if err != nil {
+		if errors.Is(err, repository.ErrSigningKeyNotFound) {
+			return m.generateKey()
+		}
+		return nil, "", err
+	}
+
+	priv, err := x509.ParsePKCS1PrivateKey([]byte(decodePEM(key.PrivateKey)))
+	if err != nil {
+		return nil, "", err
+	}
+	return priv, key.Kid, nil
+}
+
+// Rotate retires the current active key and generates a fresh one, keeping
+// the retired key around (status=retired) so tokens it signed still verify
+// until they naturally expire.
+func (m *KeyManager) Rotate() (string, error) {
+	if err := m.repo.RetireAllActive(); err != nil {
+		return "", err
+	}
+	_, kid, err := m.generateKey()
+	return kid, err
+}
+
+func (m *KeyManager) generateKey() (*rsa.PrivateKey, string, error) {
+	priv, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+	if err != nil {
+		return nil, "", err
+	}
+
+	kid, err := utils.GenerateRandomBase62(12)
+	if err != nil {
+		return nil, "", err
+	}
+
+	privPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(priv),
+	})
+	pubPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PUBLIC KEY",
+		Bytes: x509.MarshalPKCS1PublicKey(&priv.PublicKey),
+	})
+
+	record := &model.SigningKey{
+		Kid:        kid,
+		Algorithm:  "RS256",
+		PrivateKey: string(privPEM),
+		PublicKey:  string(pubPEM),
+		Status:     "active",
+		NotBefore:  time.Now(),
+	}
+	if err := m.repo.Create(record); err != nil {
+		return nil, "", err
+	}
+
+	return priv, kid, nil
+}
+
+// JWK is the public-key representation published at /.well-known/jwks.json.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns every key still valid for verification, public halves only.
+func (m *KeyManager) JWKS() (*JWKSet, error) {
+	keys, err := m.repo.FindPublishable()
+	if err != nil {
+		return nil, err
+	}
+
+	set := &JWKSet{Keys: make([]JWK, 0, len(keys))}
+	for _, k := range keys {
+		pub, err := x509.ParsePKCS1PublicKey([]byte(decodePEM(k.PublicKey)))
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: k.Algorithm,
+			Kid: k.Kid,
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		})
+	}
+	return set, nil
+}
+
+// PublicKeyForKid resolves the RSA public key for kid, for verifying tokens
+// signed by a key that may since have been rotated out. A key Revoke'd past
+// its NotAfter is refused, same as an unknown kid.
+func (m *KeyManager) PublicKeyForKid(kid string) (*rsa.PublicKey, error) {
+	key, err := m.repo.FindByKid(kid)
+	if err != nil {
+		if errors.Is(err, repository.ErrSigningKeyNotFound) {
+			return nil, errors.New("unknown signing key")
+		}
+		return nil, err
+	}
+	if key.NotAfter != nil && key.NotAfter.Before(time.Now()) {
+		return nil, errors.New("signing key revoked")
+	}
+	return x509.ParsePKCS1PublicKey(decodePEM(key.PublicKey))
+}
+
+// Revoke immediately drops kid from the published JWKS, for an admin to call
+// when a key is suspected compromised. Unlike the retirement left behind by
+// Rotate, this sets NotAfter to now instead of waiting for the key to age
+// out naturally, so tokens it signed stop verifying right away.
+func (m *KeyManager) Revoke(kid string) error {
+	key, err := m.repo.FindByKid(kid)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	key.Status = "retired"
+	key.NotAfter = &now
+	return m.repo.Update(key)
+}
+
+func decodePEM(s string) []byte {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil
+	}
+	return block.Bytes
+}
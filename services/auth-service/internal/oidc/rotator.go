@@ -0,0 +1,33 @@
+package oidc
+
+import (
+	"log"
+	"time"
+)
+
+// RunRotator calls KeyManager.Rotate on interval until stop is closed,
+// promoting a fresh signing key without an operator having to trigger it
+// by hand (see cmd/keyctl for the manual equivalent). Intended to be
+// launched as "go oidc.RunRotator(...)" from main.
+func RunRotator(m *KeyManager, interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			kid, err := m.Rotate()
+			if err != nil {
+				log.Printf("scheduled signing key rotation failed: %v", err)
+				continue
+			}
+			log.Printf("rotated signing key, new kid=%s", kid)
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,531 @@
+// Package oidc implements my-girok as an outbound OIDC/OAuth2 authorization
+// server: authorization-code + PKCE (S256), refresh_token, and
+// client_credentials grants, RS256-signed ID tokens, and the standard
+// discovery/JWKS/userinfo/revoke endpoints. This lets first- and
+// third-party apps ("resume", "portfolio", ...) authenticate against
+// my-girok instead of sharing DomainAccessToken-style ad hoc tokens.
+package oidc
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/audit"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/config"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/repository"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/scope"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	authCodeExpiry     = 2 * time.Minute
+	idTokenExpiry      = 10 * time.Minute
+	accessTokenExpiry  = 15 * time.Minute
+	refreshTokenExpiry = 30 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidClient      = errors.New("invalid client")
+	ErrInvalidRedirectURI = errors.New("invalid redirect uri")
+	ErrInvalidGrant       = errors.New("invalid grant")
+	ErrInvalidPKCE        = errors.New("invalid code verifier")
+	ErrUnsupportedGrant   = errors.New("unsupported grant type")
+	ErrUnauthorizedClient = errors.New("client not authorized for this grant type")
+)
+
+type Service struct {
+	cfg             *config.Config
+	clientRepo      *repository.OAuthClientRepository
+	authCodeRepo    *repository.AuthorizationCodeRepository
+	accessGrantRepo *repository.AccessGrantRepository
+	userRepo        repository.UserStore
+	keys            *KeyManager
+	auditLog        *audit.Logger
+}
+
+func NewService(
+	cfg *config.Config,
+	clientRepo *repository.OAuthClientRepository,
+	authCodeRepo *repository.AuthorizationCodeRepository,
+	accessGrantRepo *repository.AccessGrantRepository,
+	userRepo repository.UserStore,
+	keys *KeyManager,
+	auditLog *audit.Logger,
+) *Service {
+	return &Service{
+		cfg:             cfg,
+		clientRepo:      clientRepo,
+		authCodeRepo:    authCodeRepo,
+		accessGrantRepo: accessGrantRepo,
+		userRepo:        userRepo,
+		keys:            keys,
+		auditLog:        auditLog,
+	}
+}
+
+// AuthorizeRequest mirrors the standard /authorize query parameters.
+type AuthorizeRequest struct {
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	UserID              string
+}
+
+// Authorize validates the client/redirect pair and mints a short-lived
+// authorization code for the caller to exchange at /v1/oauth/token.
+func (s *Service) Authorize(req AuthorizeRequest) (*model.AuthorizationCode, error) {
+	client, err := s.clientRepo.FindByClientID(req.ClientID)
+	if err != nil {
+		return nil, ErrInvalidClient
+	}
+	if !s.hasRedirectURI(client, req.RedirectURI) {
+		return nil, ErrInvalidRedirectURI
+	}
+
+	requested := scope.Parse(req.Scope)
+	granted := scope.Intersect(scope.Parse(client.Scopes), requested)
+
+	code, err := utils.GenerateRandomBase62(40)
+	if err != nil {
+		return nil, err
+	}
+
+	ac := &model.AuthorizationCode{
+		Code:                code,
+		ClientID:            req.ClientID,
+		UserID:              req.UserID,
+		RedirectURI:         req.RedirectURI,
+		Scope:               granted.String(),
+		Nonce:               req.Nonce,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		ExpiresAt:           time.Now().Add(authCodeExpiry),
+	}
+	if err := s.authCodeRepo.Create(ac); err != nil {
+		return nil, err
+	}
+	return ac, nil
+}
+
+// TokenResponse mirrors RFC 6749 section 5.1 plus the OIDC id_token extension.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope"`
+}
+
+// Token handles the authorization_code, refresh_token, and
+// client_credentials grants.
+func (s *Service) Token(grantType string, params map[string]string) (*TokenResponse, error) {
+	clientID, clientSecret := params["client_id"], params["client_secret"]
+	client, err := s.authenticateClient(clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch grantType {
+	case "authorization_code":
+		return s.exchangeAuthorizationCode(client, params)
+	case "refresh_token":
+		return s.exchangeRefreshToken(client, params)
+	case "client_credentials":
+		return s.exchangeClientCredentials(client)
+	default:
+		return nil, ErrUnsupportedGrant
+	}
+}
+
+func (s *Service) exchangeAuthorizationCode(client *model.OAuthClient, params map[string]string) (*TokenResponse, error) {
+	ac, err := s.authCodeRepo.FindUnusedByCode(params["code"])
+	if err != nil {
+		return nil, ErrInvalidGrant
+	}
+	if ac.ClientID != client.ClientID || ac.RedirectURI != params["redirect_uri"] {
+		return nil, ErrInvalidGrant
+	}
+	if ac.CodeChallenge != "" {
+		if !verifyPKCE(ac.CodeChallenge, ac.CodeChallengeMethod, params["code_verifier"]) {
+			return nil, ErrInvalidPKCE
+		}
+	}
+	if err := s.authCodeRepo.MarkUsed(ac.ID); err != nil {
+		return nil, ErrInvalidGrant
+	}
+
+	user, err := s.userRepo.FindByID(ac.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(client, user, ac.Scope, ac.Nonce)
+}
+
+func (s *Service) exchangeRefreshToken(client *model.OAuthClient, params map[string]string) (*TokenResponse, error) {
+	raw := params["refresh_token"]
+	if raw == "" {
+		return nil, ErrInvalidGrant
+	}
+	hash := hashToken(raw)
+
+	grant, err := s.accessGrantRepo.FindByRefreshTokenHash(hash)
+	if err != nil || grant.ClientID != client.ClientID {
+		return nil, ErrInvalidGrant
+	}
+
+	user, err := s.userRepo.FindByID(grant.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rotate: revoke the presented refresh token before issuing a new pair.
+	if err := s.accessGrantRepo.Revoke(grant.ID); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(client, user, grant.Scope, "")
+}
+
+func (s *Service) exchangeClientCredentials(client *model.OAuthClient) (*TokenResponse, error) {
+	if !grantTypeAllowed(client, "client_credentials") {
+		return nil, ErrUnauthorizedClient
+	}
+
+	accessToken, err := s.signAccessToken(client.ClientID, "", client.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(accessTokenExpiry.Seconds()),
+		Scope:       client.Scopes,
+	}, nil
+}
+
+func (s *Service) issueTokens(client *model.OAuthClient, user *model.User, grantedScope, nonce string) (*TokenResponse, error) {
+	accessToken, err := s.signAccessToken(client.ClientID, user.ID, grantedScope)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := utils.GenerateRandomBase62(48)
+	if err != nil {
+		return nil, err
+	}
+
+	ag := &model.AccessGrant{
+		ClientID:         client.ClientID,
+		UserID:           user.ID,
+		Scope:            grantedScope,
+		RefreshTokenHash: hashToken(refreshToken),
+		ExpiresAt:        time.Now().Add(refreshTokenExpiry),
+	}
+	if err := s.accessGrantRepo.Create(ag); err != nil {
+		return nil, err
+	}
+
+	resp := &TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(accessTokenExpiry.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        grantedScope,
+	}
+
+	if scope.Parse(grantedScope).Has("openid") {
+		idToken, err := s.signIDToken(client.ClientID, user, nonce)
+		if err != nil {
+			return nil, err
+		}
+		resp.IDToken = idToken
+	}
+
+	return resp, nil
+}
+
+// idTokenClaims is the OIDC ID token payload (RFC 7519 + OpenID Connect Core).
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string `json:"nonce,omitempty"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Picture       string `json:"picture,omitempty"`
+}
+
+func (s *Service) signIDToken(clientID string, user *model.User, nonce string) (string, error) {
+	priv, kid, err := s.keys.ActiveKey()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := &idTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer(),
+			Subject:   user.ID,
+			Audience:  jwt.ClaimStrings{clientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(idTokenExpiry)),
+		},
+		Nonce:         nonce,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Name:          user.Name,
+		Picture:       user.Picture,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(priv)
+}
+
+// accessTokenClaims is the JWT presented as a bearer access token. ClientID
+// records the audience the way a resource server would check it.
+type accessTokenClaims struct {
+	jwt.RegisteredClaims
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope"`
+}
+
+func (s *Service) signAccessToken(clientID, userID, grantedScope string) (string, error) {
+	priv, kid, err := s.keys.ActiveKey()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	subject := userID
+	if subject == "" {
+		subject = clientID
+	}
+
+	claims := &accessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer(),
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(accessTokenExpiry)),
+		},
+		ClientID: clientID,
+		Scope:    grantedScope,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(priv)
+}
+
+// UserInfo returns the standard OIDC claims for the bearer access token,
+// filtered to what the token's granted scopes expose.
+func (s *Service) UserInfo(accessToken string) (map[string]interface{}, error) {
+	claims, err := s.parseAccessToken(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	granted := scope.Parse(claims.Scope)
+	info := map[string]interface{}{"sub": user.ID}
+	if granted.Has("profile") {
+		info["name"] = user.Name
+		info["username"] = user.Username
+		info["picture"] = user.Picture
+	}
+	if granted.Has("email") {
+		info["email"] = user.Email
+		info["email_verified"] = user.EmailVerified
+	}
+	return info, nil
+}
+
+func (s *Service) parseAccessToken(raw string) (*accessTokenClaims, error) {
+	token, err := jwt.ParseWithClaims(raw, &accessTokenClaims{}, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return s.keys.PublicKeyForKid(kid)
+	})
+	if err != nil {
+		return nil, ErrInvalidGrant
+	}
+	claims, ok := token.Claims.(*accessTokenClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidGrant
+	}
+	return claims, nil
+}
+
+// Revoke invalidates a refresh token so it can no longer be redeemed.
+func (s *Service) Revoke(token, userAgent, ip string) error {
+	grant, err := s.accessGrantRepo.FindByRefreshTokenHash(hashToken(token))
+	if err != nil {
+		// Per RFC 7009 section 2.2, an already-invalid token is not an error.
+		return nil
+	}
+	if err := s.accessGrantRepo.Revoke(grant.ID); err != nil {
+		return err
+	}
+	s.auditLog.Log(audit.ActionTokenRevoke, grant.UserID, grant.UserID, ip, userAgent, map[string]any{"clientId": grant.ClientID})
+	return nil
+}
+
+// IntrospectResponse mirrors RFC 7662 section 2.2. Inactive tokens are
+// returned as {"active": false} with every other field omitted.
+type IntrospectResponse struct {
+	Active    bool   `json:"active"`
+	ClientID  string `json:"client_id,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+}
+
+// Introspect reports whether a bearer access token is currently valid (RFC
+// 7662). Unlike Revoke, it never errors; an unparseable or expired token
+// just comes back inactive.
+func (s *Service) Introspect(token string) *IntrospectResponse {
+	claims, err := s.parseAccessToken(token)
+	if err != nil {
+		return &IntrospectResponse{Active: false}
+	}
+	return &IntrospectResponse{
+		Active:    true,
+		ClientID:  claims.ClientID,
+		Scope:     claims.Scope,
+		Sub:       claims.Subject,
+		TokenType: "Bearer",
+		Exp:       claims.ExpiresAt.Unix(),
+		Iss:       claims.Issuer,
+	}
+}
+
+// AuthorizedApp is one third-party client a user has granted access to,
+// summarized from their still-active AccessGrant rows.
+type AuthorizedApp struct {
+	ClientID  string    `json:"clientId"`
+	Name      string    `json:"name"`
+	Scope     string    `json:"scope"`
+	GrantedAt time.Time `json:"grantedAt"`
+}
+
+// AuthorizedApps lists the distinct clients userID has an active grant
+// with, newest first, for a "connected apps" settings page.
+func (s *Service) AuthorizedApps(userID string) ([]AuthorizedApp, error) {
+	grants, err := s.accessGrantRepo.FindActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(grants))
+	apps := make([]AuthorizedApp, 0, len(grants))
+	for _, g := range grants {
+		if seen[g.ClientID] {
+			continue
+		}
+		seen[g.ClientID] = true
+
+		name := g.ClientID
+		if client, err := s.clientRepo.FindByClientID(g.ClientID); err == nil {
+			name = client.Name
+		}
+		apps = append(apps, AuthorizedApp{
+			ClientID:  g.ClientID,
+			Name:      name,
+			Scope:     g.Scope,
+			GrantedAt: g.CreatedAt,
+		})
+	}
+	return apps, nil
+}
+
+// Deauthorize revokes every grant userID has given clientID (Mattermost
+// calls this "deauthorize OAuth app"), independent of who owns the client.
+func (s *Service) Deauthorize(userID, clientID, userAgent, ip string) error {
+	if err := s.accessGrantRepo.RevokeAllByUserAndClient(userID, clientID); err != nil {
+		return err
+	}
+	s.auditLog.Log(audit.ActionAppDeauthorize, userID, userID, ip, userAgent, map[string]any{"clientId": clientID})
+	return nil
+}
+
+func (s *Service) issuer() string {
+	if s.cfg.OAuth.FrontendURL != "" {
+		return s.cfg.OAuth.FrontendURL
+	}
+	return "https://girok.dev"
+}
+
+func (s *Service) authenticateClient(clientID, clientSecret string) (*model.OAuthClient, error) {
+	client, err := s.clientRepo.FindByClientID(clientID)
+	if err != nil {
+		return nil, ErrInvalidClient
+	}
+	if client.Confidential {
+		if clientSecret == "" || bcrypt.CompareHashAndPassword([]byte(client.ClientSecretHash), []byte(clientSecret)) != nil {
+			return nil, ErrInvalidClient
+		}
+	}
+	return client, nil
+}
+
+func (s *Service) hasRedirectURI(client *model.OAuthClient, uri string) bool {
+	for _, registered := range strings.Fields(client.RedirectURIs) {
+		if registered == uri {
+			return true
+		}
+	}
+	return false
+}
+
+func grantTypeAllowed(client *model.OAuthClient, grantType string) bool {
+	for _, g := range strings.Fields(client.GrantTypes) {
+		if g == grantType {
+			return true
+		}
+	}
+	return false
+}
+
+func verifyPKCE(challenge, method, verifier string) bool {
+	if verifier == "" {
+		return false
+	}
+	if method == "" {
+		method = "plain"
+	}
+	switch method {
+	case "S256":
+		sum := sha256.Sum256([]byte(verifier))
+		computed := base64.RawURLEncoding.EncodeToString(sum[:])
+		return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+	case "plain":
+		return subtle.ConstantTimeCompare([]byte(verifier), []byte(challenge)) == 1
+	default:
+		return false
+	}
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return fmt.Sprintf("%x", sum)
+}
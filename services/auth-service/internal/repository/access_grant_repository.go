@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"gorm.io/gorm"
+)
+
+var ErrAccessGrantNotFound = errors.New("access grant not found")
+
+type AccessGrantRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessGrantRepository(db *gorm.DB) *AccessGrantRepository {
+	return &AccessGrantRepository{db: db}
+}
+
+func (r *AccessGrantRepository) Create(grant *model.AccessGrant) error {
+	return r.db.Create(grant).Error
+}
+
+func (r *AccessGrantRepository) FindByRefreshTokenHash(hash string) (*model.AccessGrant, error) {
+	var grant model.AccessGrant
+	result := r.db.Where("refresh_token_hash = ? AND revoked_at IS NULL AND expires_at > ?", hash, time.Now()).First(&grant)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrAccessGrantNotFound
+		}
+		return nil, result.Error
+	}
+	return &grant, nil
+}
+
+func (r *AccessGrantRepository) Revoke(id string) error {
+	return r.db.Model(&model.AccessGrant{}).Where("id = ?", id).Update("revoked_at", time.Now()).Error
+}
+
+func (r *AccessGrantRepository) RevokeByRefreshTokenHash(hash string) error {
+	return r.db.Model(&model.AccessGrant{}).Where("refresh_token_hash = ?", hash).Update("revoked_at", time.Now()).Error
+}
+
+// FindActiveByUser returns every non-revoked, unexpired grant issued to
+// userID, across all clients, for the "authorized apps" listing.
+func (r *AccessGrantRepository) FindActiveByUser(userID string) ([]model.AccessGrant, error) {
+	var grants []model.AccessGrant
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").Find(&grants).Error
+	return grants, err
+}
+
+// RevokeAllByUserAndClient revokes every grant userID holds for clientID,
+// the "deauthorize this app" action.
+func (r *AccessGrantRepository) RevokeAllByUserAndClient(userID, clientID string) error {
+	return r.db.Model(&model.AccessGrant{}).
+		Where("user_id = ? AND client_id = ? AND revoked_at IS NULL", userID, clientID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"gorm.io/gorm"
+)
+
+var ErrAccessTokenNotFound = errors.New("access token not found")
+
+type AccessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessTokenRepository(db *gorm.DB) *AccessTokenRepository {
+	return &AccessTokenRepository{db: db}
+}
+
+func (r *AccessTokenRepository) Create(token *model.AccessToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *AccessTokenRepository) FindByHash(tokenHash string) (*model.AccessToken, error) {
+	var token model.AccessToken
+	result := r.db.Where("token_hash = ?", tokenHash).First(&token)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrAccessTokenNotFound
+		}
+		return nil, result.Error
+	}
+	return &token, nil
+}
+
+func (r *AccessTokenRepository) FindByUserID(userID string) ([]model.AccessToken, error) {
+	var tokens []model.AccessToken
+	result := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tokens, nil
+}
+
+// Delete removes a token owned by userID, so one caller can't revoke another
+// user's token by guessing its ID.
+func (r *AccessTokenRepository) Delete(id, userID string) error {
+	result := r.db.Delete(&model.AccessToken{}, "id = ? AND user_id = ?", id, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAccessTokenNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed stamps LastUsedAt on every PAT-authenticated request. Best
+// effort by design: it runs on the hot auth path, so a failure here logs
+// upstream (see service.AccessTokenService.ResolvePAT) rather than denying
+// the request.
+func (r *AccessTokenRepository) TouchLastUsed(id string, at time.Time) error {
+	return r.db.Model(&model.AccessToken{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"gorm.io/gorm"
+)
+
+// auditChainLockKey is the pg_advisory_xact_lock key CreateChained
+// serializes on. Locking the "latest" row with SELECT ... FOR UPDATE
+// doesn't block a concurrent transaction inserting a brand-new row
+// elsewhere in the table, so the chain would still fork under true
+// concurrency; a single well-known advisory lock held for the whole
+// read-then-insert closes that gap regardless of how many replicas are
+// running, since the lock lives in Postgres rather than any one process.
+const auditChainLockKey = 72_819_004_417
+
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+func (r *AuditLogRepository) Create(entry *model.AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+// CreateChained takes a transaction-scoped advisory lock, reads the current
+// chain head, passes its Hash to hashFn as the new entry's PrevHash, and
+// inserts entry - all before releasing the lock at commit, so concurrent
+// callers (including across replicas, since the lock lives in Postgres
+// rather than in any one process's memory) serialize on the whole
+// read-then-insert instead of racing to read the same head and fork the
+// chain. Locking just the newest row (SELECT ... FOR UPDATE) isn't enough
+// for that: it doesn't block a second transaction inserting a brand-new row
+// concurrently, only one that tries to update the row already locked.
+// hashFn must set entry.PrevHash/entry.Hash before returning; it exists so
+// the actual hashing (which fields, which algorithm) stays in the audit
+// package rather than leaking into the repository layer.
+func (r *AuditLogRepository) CreateChained(entry *model.AuditLog, hashFn func(prevHash string)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLockKey).Error; err != nil {
+			return err
+		}
+		return appendChained(tx, entry, hashFn)
+	})
+}
+
+// appendChained does the actual read-head/insert under the assumption the
+// caller already holds whatever serialization CreateChained needs; split out
+// so that locking step is the only part of CreateChained that's
+// Postgres-specific and untestable outside it.
+func appendChained(tx *gorm.DB, entry *model.AuditLog, hashFn func(prevHash string)) error {
+	var head model.AuditLog
+	err := tx.Model(&model.AuditLog{}).
+		Select("hash").
+		Order("created_at DESC, id DESC").
+		First(&head).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	hashFn(head.Hash)
+	return tx.Create(entry).Error
+}
+
+// AuditLogFilter narrows List to a slice of the table. Zero values are
+// "no filter" for that field.
+type AuditLogFilter struct {
+	ActorUserID string
+	Action      string
+	From        time.Time
+	To          time.Time
+
+	// Cursor is the ID of the last entry from a previous page; results are
+	// strictly older than it. Empty fetches the first page.
+	Cursor string
+	Limit  int
+}
+
+// List returns entries newest-first matching the filter, plus the cursor to
+// pass back for the next page (empty once exhausted).
+func (r *AuditLogRepository) List(filter AuditLogFilter) ([]model.AuditLog, string, error) {
+	limit := filter.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	query := r.db.Model(&model.AuditLog{}).Order("created_at DESC, id DESC")
+
+	if filter.ActorUserID != "" {
+		query = query.Where("actor_user_id = ?", filter.ActorUserID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at <= ?", filter.To)
+	}
+	if filter.Cursor != "" {
+		var cursorEntry model.AuditLog
+		if err := r.db.Select("created_at").First(&cursorEntry, "id = ?", filter.Cursor).Error; err != nil {
+			return nil, "", err
+		}
+		query = query.Where("created_at < ?", cursorEntry.CreatedAt)
+	}
+
+	var entries []model.AuditLog
+	if err := query.Limit(limit + 1).Find(&entries).Error; err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(entries) > limit {
+		nextCursor = entries[limit-1].ID
+		entries = entries[:limit]
+	}
+
+	return entries, nextCursor, nil
+}
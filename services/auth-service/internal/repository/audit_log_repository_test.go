@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestAppendChainedLinksSequentialEntries pins down the hash-linking
+// contract CreateChained promises callers: each entry's PrevHash must be the
+// immediately preceding entry's Hash, not an earlier or empty one. It
+// exercises appendChained directly rather than CreateChained, since
+// CreateChained's serialization is a pg_advisory_xact_lock call sqlite has
+// no equivalent for - that part of this repository is Postgres-only and the
+// concurrent-fork scenario it exists to prevent needs a real Postgres
+// instance to reproduce; this test only covers the sequential hash-linking
+// logic shared by both backends.
+func TestAppendChainedLinksSequentialEntries(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.AuditLog{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	hashFor := func(prevHash, action string) string { return prevHash + ":" + action }
+
+	entries := []string{"login", "logout", "login"}
+	var lastHash string
+	for _, action := range entries {
+		entry := &model.AuditLog{Action: action}
+		if err := appendChained(db, entry, func(prevHash string) {
+			entry.PrevHash = prevHash
+			entry.Hash = hashFor(prevHash, action)
+		}); err != nil {
+			t.Fatalf("appendChained(%q): %v", action, err)
+		}
+		if entry.PrevHash != lastHash {
+			t.Errorf("action %q: PrevHash = %q, want %q", action, entry.PrevHash, lastHash)
+		}
+		lastHash = entry.Hash
+	}
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAuthProviderConfigNotFound = errors.New("oauth config not found")
+)
+
+type AuthProviderConfigRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthProviderConfigRepository(db *gorm.DB) *AuthProviderConfigRepository {
+	return &AuthProviderConfigRepository{db: db}
+}
+
+func (r *AuthProviderConfigRepository) Create(config *model.AuthProviderConfig) error {
+	return r.db.Create(config).Error
+}
+
+func (r *AuthProviderConfigRepository) FindByProvider(provider model.AuthProvider) (*model.AuthProviderConfig, error) {
+	var config model.AuthProviderConfig
+	result := r.db.Where("provider = ?", provider).First(&config)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrAuthProviderConfigNotFound
+		}
+		return nil, result.Error
+	}
+	return &config, nil
+}
+
+func (r *AuthProviderConfigRepository) FindAll() ([]model.AuthProviderConfig, error) {
+	var configs []model.AuthProviderConfig
+	result := r.db.Order("provider ASC").Find(&configs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return configs, nil
+}
+
+func (r *AuthProviderConfigRepository) FindEnabled() ([]model.AuthProviderConfig, error) {
+	var configs []model.AuthProviderConfig
+	result := r.db.Where("enabled = ?", true).Order("provider ASC").Find(&configs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return configs, nil
+}
+
+func (r *AuthProviderConfigRepository) Update(config *model.AuthProviderConfig) error {
+	return r.db.Save(config).Error
+}
+
+func (r *AuthProviderConfigRepository) Delete(provider model.AuthProvider) error {
+	result := r.db.Delete(&model.AuthProviderConfig{}, "provider = ?", provider)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAuthProviderConfigNotFound
+	}
+	return nil
+}
+
+func (r *AuthProviderConfigRepository) IsProviderEnabled(provider model.AuthProvider) (bool, error) {
+	config, err := r.FindByProvider(provider)
+	if err != nil {
+		if errors.Is(err, ErrAuthProviderConfigNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return config.Enabled, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"gorm.io/gorm"
+)
+
+var ErrAuthorizationCodeNotFound = errors.New("authorization code not found")
+
+type AuthorizationCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthorizationCodeRepository(db *gorm.DB) *AuthorizationCodeRepository {
+	return &AuthorizationCodeRepository{db: db}
+}
+
+func (r *AuthorizationCodeRepository) Create(code *model.AuthorizationCode) error {
+	return r.db.Create(code).Error
+}
+
+// FindUnusedByCode looks up a live, unused code. Callers must mark it used
+// (MarkUsed) atomically with redemption to prevent replay.
+func (r *AuthorizationCodeRepository) FindUnusedByCode(code string) (*model.AuthorizationCode, error) {
+	var ac model.AuthorizationCode
+	result := r.db.Where("code = ? AND used = ? AND expires_at > ?", code, false, time.Now()).First(&ac)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrAuthorizationCodeNotFound
+		}
+		return nil, result.Error
+	}
+	return &ac, nil
+}
+
+func (r *AuthorizationCodeRepository) MarkUsed(id string) error {
+	result := r.db.Model(&model.AuthorizationCode{}).Where("id = ? AND used = ?", id, false).Update("used", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAuthorizationCodeNotFound
+	}
+	return nil
+}
+
+func (r *AuthorizationCodeRepository) DeleteExpired() error {
+	return r.db.Delete(&model.AuthorizationCode{}, "expires_at <= ?", time.Now()).Error
+}
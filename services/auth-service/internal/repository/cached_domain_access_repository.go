@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/cache"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+)
+
+// CachedDomainAccessRepository wraps a DomainAccessStore with a cache-first
+// read path on the hot FindByToken lookup (every shared-resource request
+// hits it), write-through on Create/Update, and invalidation on DeleteByID.
+// FindByUserAndDomain/FindByUserID/DeleteExpired pass straight through:
+// they're not the per-request hot path FindByToken is.
+type CachedDomainAccessRepository struct {
+	inner DomainAccessStore
+	cache cache.Cache
+}
+
+func NewCachedDomainAccessRepository(inner DomainAccessStore, c cache.Cache) *CachedDomainAccessRepository {
+	return &CachedDomainAccessRepository{inner: inner, cache: c}
+}
+
+var _ DomainAccessStore = (*CachedDomainAccessRepository)(nil)
+
+func domainAccessCacheKey(token string) string {
+	return "domain-access:token:" + token
+}
+
+// domainAccessIDKey indexes id -> raw token so DeleteByID (which only gets
+// an ID, per DomainAccessService.RevokeAccess) can still find and evict the
+// token-keyed cache entry above.
+func domainAccessIDKey(id string) string {
+	return "domain-access:id:" + id
+}
+
+func (r *CachedDomainAccessRepository) Create(token *model.DomainAccessToken) error {
+	if err := r.inner.Create(token); err != nil {
+		return err
+	}
+	r.put(token)
+	return nil
+}
+
+func (r *CachedDomainAccessRepository) FindByToken(token string) (*model.DomainAccessToken, error) {
+	ctx := context.Background()
+	key := domainAccessCacheKey(token)
+
+	if cached, found, err := r.cache.Get(ctx, key); err == nil && found {
+		if cached == cache.Negative {
+			return nil, ErrDomainAccessNotFound
+		}
+		var domainAccess model.DomainAccessToken
+		if err := json.Unmarshal([]byte(cached), &domainAccess); err == nil {
+			return &domainAccess, nil
+		}
+	}
+
+	domainAccess, err := r.inner.FindByToken(token)
+	if err != nil {
+		if err == ErrDomainAccessNotFound {
+			r.cache.Set(ctx, key, cache.Negative, cache.NegativeTTL)
+		}
+		return nil, err
+	}
+	r.put(domainAccess)
+	return domainAccess, nil
+}
+
+func (r *CachedDomainAccessRepository) FindByUserAndDomain(userID, domain string) (*model.DomainAccessToken, error) {
+	return r.inner.FindByUserAndDomain(userID, domain)
+}
+
+func (r *CachedDomainAccessRepository) FindByUserID(userID string) ([]model.DomainAccessToken, error) {
+	return r.inner.FindByUserID(userID)
+}
+
+func (r *CachedDomainAccessRepository) DeleteByID(id string) error {
+	if err := r.inner.DeleteByID(id); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	idKey := domainAccessIDKey(id)
+	if token, found, err := r.cache.Get(ctx, idKey); err == nil && found {
+		r.cache.Delete(ctx, domainAccessCacheKey(token))
+		r.cache.Delete(ctx, idKey)
+	}
+	return nil
+}
+
+func (r *CachedDomainAccessRepository) DeleteExpired() error {
+	return r.inner.DeleteExpired()
+}
+
+func (r *CachedDomainAccessRepository) Update(token *model.DomainAccessToken) error {
+	if err := r.inner.Update(token); err != nil {
+		return err
+	}
+	r.put(token)
+	return nil
+}
+
+func (r *CachedDomainAccessRepository) put(token *model.DomainAccessToken) {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return
+	}
+	ttl := time.Until(token.ExpiresAt)
+	if ttl <= 0 {
+		return
+	}
+	ctx := context.Background()
+	r.cache.Set(ctx, domainAccessCacheKey(token.Token), string(data), ttl)
+	r.cache.Set(ctx, domainAccessIDKey(token.ID), token.Token, ttl)
+}
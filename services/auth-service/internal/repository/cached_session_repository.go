@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/cache"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+)
+
+// CachedSessionRepository wraps a SessionStore with a cache-first read path
+// on FindByTokenFamilyID, write-through on Create/RotateTokenFamily, and
+// invalidation on DeleteByTokenFamilyID (and on the reuse-detected branch of
+// RotateTokenFamily, which deletes the whole family).
+type CachedSessionRepository struct {
+	inner SessionStore
+	cache cache.Cache
+}
+
+func NewCachedSessionRepository(inner SessionStore, c cache.Cache) *CachedSessionRepository {
+	return &CachedSessionRepository{inner: inner, cache: c}
+}
+
+var _ SessionStore = (*CachedSessionRepository)(nil)
+
+func sessionCacheKey(familyID string) string {
+	return "session:family:" + familyID
+}
+
+func (r *CachedSessionRepository) Create(session *model.Session) error {
+	if err := r.inner.Create(session); err != nil {
+		return err
+	}
+	r.put(session)
+	return nil
+}
+
+func (r *CachedSessionRepository) FindByTokenFamilyID(familyID string) (*model.Session, error) {
+	ctx := context.Background()
+	key := sessionCacheKey(familyID)
+
+	if cached, found, err := r.cache.Get(ctx, key); err == nil && found {
+		if cached == cache.Negative {
+			return nil, ErrSessionNotFound
+		}
+		var session model.Session
+		if err := json.Unmarshal([]byte(cached), &session); err == nil {
+			return &session, nil
+		}
+	}
+
+	session, err := r.inner.FindByTokenFamilyID(familyID)
+	if err != nil {
+		if err == ErrSessionNotFound {
+			r.cache.Set(ctx, key, cache.Negative, cache.NegativeTTL)
+		}
+		return nil, err
+	}
+	r.put(session)
+	return session, nil
+}
+
+func (r *CachedSessionRepository) RotateTokenFamily(familyID, presentedHash, newHash string, newExpiresAt time.Time) (*model.Session, bool, error) {
+	session, reused, err := r.inner.RotateTokenFamily(familyID, presentedHash, newHash, newExpiresAt)
+	if err != nil {
+		return nil, reused, err
+	}
+	if reused {
+		// The whole family was deleted server-side; drop the cache entry
+		// instead of repopulating it with the now-superseded hash.
+		r.cache.Delete(context.Background(), sessionCacheKey(familyID))
+		return session, true, nil
+	}
+	r.put(session)
+	return session, false, nil
+}
+
+func (r *CachedSessionRepository) DeleteByTokenFamilyID(familyID string) error {
+	if err := r.inner.DeleteByTokenFamilyID(familyID); err != nil {
+		return err
+	}
+	r.cache.Delete(context.Background(), sessionCacheKey(familyID))
+	return nil
+}
+
+// FindByUserID lists sessions for the security/sessions self-service
+// endpoints; not worth caching, every other lookup here is keyed by
+// TokenFamilyID, not UserID.
+func (r *CachedSessionRepository) FindByUserID(userID string) ([]model.Session, error) {
+	return r.inner.FindByUserID(userID)
+}
+
+// DeleteByID revokes a single session by ID. The cache is keyed by
+// TokenFamilyID rather than session ID, so the entry is left to expire on
+// its own TTL instead of being looked up here just to invalidate it.
+func (r *CachedSessionRepository) DeleteByID(id, userID string) error {
+	return r.inner.DeleteByID(id, userID)
+}
+
+func (r *CachedSessionRepository) put(session *model.Session) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return
+	}
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return
+	}
+	r.cache.Set(context.Background(), sessionCacheKey(session.TokenFamilyID), string(data), ttl)
+}
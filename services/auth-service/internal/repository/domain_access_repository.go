@@ -12,10 +12,26 @@ var (
 	ErrDomainAccessNotFound = errors.New("domain access token not found")
 )
 
+// DomainAccessStore is the storage contract DomainAccessService depends on.
+// *DomainAccessRepository (GORM/Postgres) is the source of truth;
+// CachedDomainAccessRepository wraps it with a cache-first read path on the
+// hot FindByToken lookup (see internal/cache).
+type DomainAccessStore interface {
+	Create(token *model.DomainAccessToken) error
+	FindByToken(token string) (*model.DomainAccessToken, error)
+	FindByUserAndDomain(userID, domain string) (*model.DomainAccessToken, error)
+	FindByUserID(userID string) ([]model.DomainAccessToken, error)
+	DeleteByID(id string) error
+	DeleteExpired() error
+	Update(token *model.DomainAccessToken) error
+}
+
 type DomainAccessRepository struct {
 	db *gorm.DB
 }
 
+var _ DomainAccessStore = (*DomainAccessRepository)(nil)
+
 func NewDomainAccessRepository(db *gorm.DB) *DomainAccessRepository {
 	return &DomainAccessRepository{db: db}
 }
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"gorm.io/gorm"
+)
+
+var ErrOAuthClientNotFound = errors.New("oauth client not found")
+
+type OAuthClientRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthClientRepository(db *gorm.DB) *OAuthClientRepository {
+	return &OAuthClientRepository{db: db}
+}
+
+func (r *OAuthClientRepository) Create(client *model.OAuthClient) error {
+	return r.db.Create(client).Error
+}
+
+func (r *OAuthClientRepository) FindByClientID(clientID string) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	result := r.db.Where("client_id = ?", clientID).First(&client)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrOAuthClientNotFound
+		}
+		return nil, result.Error
+	}
+	return &client, nil
+}
+
+func (r *OAuthClientRepository) FindByOwner(ownerUserID string) ([]model.OAuthClient, error) {
+	var clients []model.OAuthClient
+	result := r.db.Where("owner_user_id = ?", ownerUserID).Order("created_at DESC").Find(&clients)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return clients, nil
+}
+
+func (r *OAuthClientRepository) Update(client *model.OAuthClient) error {
+	return r.db.Save(client).Error
+}
+
+func (r *OAuthClientRepository) Delete(clientID string) error {
+	result := r.db.Delete(&model.OAuthClient{}, "client_id = ?", clientID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOAuthClientNotFound
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"gorm.io/gorm"
+)
+
+var ErrOAuthExchangeCodeNotFound = errors.New("oauth exchange code not found or expired")
+
+type OAuthExchangeCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthExchangeCodeRepository(db *gorm.DB) *OAuthExchangeCodeRepository {
+	return &OAuthExchangeCodeRepository{db: db}
+}
+
+func (r *OAuthExchangeCodeRepository) Create(code *model.OAuthExchangeCode) error {
+	return r.db.Create(code).Error
+}
+
+// Consume looks up an unexpired code and deletes it in the same
+// transaction, so it can only be redeemed once. The delete is conditioned
+// on the same code/expiry predicate and its RowsAffected checked, so two
+// concurrent calls racing on the same code can't both read the row before
+// either deletes it and both walk away with a live token pair.
+func (r *OAuthExchangeCodeRepository) Consume(code string) (*model.OAuthExchangeCode, error) {
+	var record model.OAuthExchangeCode
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("code = ? AND expires_at > ?", code, time.Now()).First(&record)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return ErrOAuthExchangeCodeNotFound
+			}
+			return result.Error
+		}
+		del := tx.Where("code = ? AND expires_at > ?", code, time.Now()).Delete(&model.OAuthExchangeCode{})
+		if del.Error != nil {
+			return del.Error
+		}
+		if del.RowsAffected == 0 {
+			return ErrOAuthExchangeCodeNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
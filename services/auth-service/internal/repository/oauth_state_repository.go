@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"gorm.io/gorm"
+)
+
+var ErrOAuthStateNotFound = errors.New("oauth state not found or expired")
+
+type OAuthStateRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthStateRepository(db *gorm.DB) *OAuthStateRepository {
+	return &OAuthStateRepository{db: db}
+}
+
+func (r *OAuthStateRepository) Create(state *model.OAuthState) error {
+	return r.db.Create(state).Error
+}
+
+// Consume looks up an unexpired state value for provider and deletes it in
+// the same transaction, so a given state can only be redeemed once, and
+// returns the consumed record so its CodeVerifier can still be used for the
+// PKCE token exchange that follows. The delete is conditioned on the same
+// provider/state/expiry predicate and its RowsAffected checked, so two
+// concurrent calls racing on the same state can't both read the row before
+// either deletes it.
+func (r *OAuthStateRepository) Consume(provider model.AuthProvider, state string) (*model.OAuthState, error) {
+	var record model.OAuthState
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("provider = ? AND state = ? AND expires_at > ?", provider, state, time.Now()).First(&record)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return ErrOAuthStateNotFound
+			}
+			return result.Error
+		}
+		del := tx.Where("provider = ? AND state = ? AND expires_at > ?", provider, state, time.Now()).Delete(&model.OAuthState{})
+		if del.Error != nil {
+			return del.Error
+		}
+		if del.RowsAffected == 0 {
+			return ErrOAuthStateNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// DeleteExpired prunes abandoned authorization attempts; callers may run
+// this on a schedule, it is not required for correctness since Consume
+// already filters on ExpiresAt.
+func (r *OAuthStateRepository) DeleteExpired() error {
+	return r.db.Where("expires_at <= ?", time.Now()).Delete(&model.OAuthState{}).Error
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"github.com/beegy-labs/my-girok/services/auth-service/plugin/userstorage"
+)
+
+// PluginUserStore adapts a userstorage.Store (backed by a subprocess plugin)
+// to the UserStore contract the rest of the service depends on, translating
+// between model.User and the plugin wire record on every call.
+type PluginUserStore struct {
+	store userstorage.Store
+}
+
+func NewPluginUserStore(store userstorage.Store) *PluginUserStore {
+	return &PluginUserStore{store: store}
+}
+
+var _ UserStore = (*PluginUserStore)(nil)
+
+func (p *PluginUserStore) Create(user *model.User) error {
+	rec := toRecord(user)
+	if err := p.store.Create(rec); err != nil {
+		return translateErr(err)
+	}
+	fromRecord(rec, user)
+	return nil
+}
+
+func (p *PluginUserStore) FindByID(id string) (*model.User, error) {
+	rec, err := p.store.FindByID(id)
+	if err != nil {
+		return nil, translateErr(err)
+	}
+	return recordToUser(rec), nil
+}
+
+func (p *PluginUserStore) FindByEmail(email string) (*model.User, error) {
+	rec, err := p.store.FindByEmail(email)
+	if err != nil {
+		return nil, translateErr(err)
+	}
+	return recordToUser(rec), nil
+}
+
+func (p *PluginUserStore) FindByUsername(username string) (*model.User, error) {
+	rec, err := p.store.FindByUsername(username)
+	if err != nil {
+		return nil, translateErr(err)
+	}
+	return recordToUser(rec), nil
+}
+
+func (p *PluginUserStore) FindByExternalID(externalID string) (*model.User, error) {
+	rec, err := p.store.FindByExternalID(externalID)
+	if err != nil {
+		return nil, translateErr(err)
+	}
+	return recordToUser(rec), nil
+}
+
+func (p *PluginUserStore) FindByProviderID(provider model.AuthProvider, providerID string) (*model.User, error) {
+	rec, err := p.store.FindByProviderID(string(provider), providerID)
+	if err != nil {
+		return nil, translateErr(err)
+	}
+	return recordToUser(rec), nil
+}
+
+func (p *PluginUserStore) Update(user *model.User) error {
+	return translateErr(p.store.Update(toRecord(user)))
+}
+
+func (p *PluginUserStore) Delete(id string) error {
+	return translateErr(p.store.Delete(id))
+}
+
+func (p *PluginUserStore) List(page, perPage int) ([]model.User, int64, error) {
+	records, total, err := p.store.List(page, perPage)
+	if err != nil {
+		return nil, 0, translateErr(err)
+	}
+	users := make([]model.User, len(records))
+	for i := range records {
+		users[i] = *recordToUser(&records[i])
+	}
+	return users, total, nil
+}
+
+func translateErr(err error) error {
+	if err == userstorage.ErrNotFound {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+func toRecord(user *model.User) *userstorage.Record {
+	return &userstorage.Record{
+		ID:            user.ID,
+		ExternalID:    user.ExternalID,
+		Email:         user.Email,
+		Username:      user.Username,
+		PasswordHash:  user.PasswordHash,
+		Role:          string(user.Role),
+		Provider:      string(user.Provider),
+		ProviderID:    user.ProviderID,
+		Name:          user.Name,
+		Picture:       user.Picture,
+		EmailVerified: user.EmailVerified,
+		IsActive:      user.IsActive,
+	}
+}
+
+func recordToUser(rec *userstorage.Record) *model.User {
+	user := &model.User{}
+	fromRecord(rec, user)
+	return user
+}
+
+func fromRecord(rec *userstorage.Record, user *model.User) {
+	user.ID = rec.ID
+	user.ExternalID = rec.ExternalID
+	user.Email = rec.Email
+	user.Username = rec.Username
+	user.PasswordHash = rec.PasswordHash
+	user.Role = model.Role(rec.Role)
+	user.Provider = model.AuthProvider(rec.Provider)
+	user.ProviderID = rec.ProviderID
+	user.Name = rec.Name
+	user.Picture = rec.Picture
+	user.EmailVerified = rec.EmailVerified
+	user.IsActive = rec.IsActive
+}
@@ -10,10 +10,29 @@ import (
 
 var ErrSessionNotFound = errors.New("session not found")
 
+// SessionStore is the storage contract AuthService depends on for refresh
+// token sessions. *SessionRepository (GORM/Postgres) is the source of
+// truth; CachedSessionRepository wraps it with a cache-first read path (see
+// internal/cache) without AuthService knowing the difference.
+type SessionStore interface {
+	Create(session *model.Session) error
+	FindByTokenFamilyID(familyID string) (*model.Session, error)
+	RotateTokenFamily(familyID, presentedHash, newHash string, newExpiresAt time.Time) (*model.Session, bool, error)
+	DeleteByTokenFamilyID(familyID string) error
+	// FindByUserID lists a user's active (non-expired) sessions for
+	// GET /v1/users/me/sessions.
+	FindByUserID(userID string) ([]model.Session, error)
+	// DeleteByID revokes a single session owned by userID, so one caller
+	// can't revoke another user's session by guessing its ID.
+	DeleteByID(id, userID string) error
+}
+
 type SessionRepository struct {
 	db *gorm.DB
 }
 
+var _ SessionStore = (*SessionRepository)(nil)
+
 func NewSessionRepository(db *gorm.DB) *SessionRepository {
 	return &SessionRepository{db: db}
 }
@@ -22,9 +41,9 @@ func (r *SessionRepository) Create(session *model.Session) error {
 	return r.db.Create(session).Error
 }
 
-func (r *SessionRepository) FindByRefreshToken(token string) (*model.Session, error) {
+func (r *SessionRepository) FindByTokenFamilyID(familyID string) (*model.Session, error) {
 	var session model.Session
-	result := r.db.Where("refresh_token = ? AND expires_at > ?", token, time.Now()).First(&session)
+	result := r.db.Where("token_family_id = ? AND expires_at > ?", familyID, time.Now()).First(&session)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, ErrSessionNotFound
@@ -34,9 +53,67 @@ func (r *SessionRepository) FindByRefreshToken(token string) (*model.Session, er
 	return &session, nil
 }
 
+// RotateTokenFamily atomically advances a session's refresh token: if
+// presentedHash matches the family's CurrentTokenHash it is replaced with
+// newHash and the session's expiry extended; otherwise the presented token
+// is a replay of an already-rotated token and the entire family is deleted,
+// forcing the client to re-login. The returned bool reports whether reuse
+// was detected.
+func (r *SessionRepository) RotateTokenFamily(familyID, presentedHash, newHash string, newExpiresAt time.Time) (*model.Session, bool, error) {
+	var session model.Session
+	reused := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("token_family_id = ? AND expires_at > ?", familyID, time.Now()).First(&session).Error; err != nil {
+			return err
+		}
+
+		// Conditioned on current_token_hash so two concurrent callers
+		// presenting the same still-current token can't both pass the
+		// reuse check and both rotate: only the first UPDATE affects a
+		// row, the same CAS pattern authorization_code_repository.go's
+		// MarkUsed and the oauth_state/oauth_exchange_code repositories
+		// use, just via UPDATE+RowsAffected instead of DELETE+RowsAffected.
+		result := tx.Model(&model.Session{}).
+			Where("token_family_id = ? AND current_token_hash = ?", familyID, presentedHash).
+			Updates(map[string]interface{}{
+				"current_token_hash": newHash,
+				"expires_at":         newExpiresAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// presentedHash no longer matches CurrentTokenHash by the time
+			// this UPDATE ran - either a genuine reuse attempt, or a
+			// concurrent caller rotated past it a moment ago. Either way
+			// the presented token is dead, so the whole family is revoked
+			// rather than risk letting a real attacker keep retrying.
+			reused = true
+			return tx.Delete(&model.Session{}, "token_family_id = ?", familyID).Error
+		}
+
+		session.CurrentTokenHash = newHash
+		session.ExpiresAt = newExpiresAt
+		return nil
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, ErrSessionNotFound
+		}
+		return nil, reused, err
+	}
+	return &session, reused, nil
+}
+
+func (r *SessionRepository) DeleteByTokenFamilyID(familyID string) error {
+	return r.db.Delete(&model.Session{}, "token_family_id = ?", familyID).Error
+}
+
 func (r *SessionRepository) FindByUserID(userID string) ([]model.Session, error) {
 	var sessions []model.Session
-	result := r.db.Where("user_id = ? AND expires_at > ?", userID, time.Now()).Find(&sessions)
+	result := r.db.Where("user_id = ? AND expires_at > ?", userID, time.Now()).Order("created_at DESC").Find(&sessions)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -47,8 +124,16 @@ func (r *SessionRepository) Delete(id string) error {
 	return r.db.Delete(&model.Session{}, "id = ?", id).Error
 }
 
-func (r *SessionRepository) DeleteByRefreshToken(token string) error {
-	return r.db.Delete(&model.Session{}, "refresh_token = ?", token).Error
+// DeleteByID revokes a single session owned by userID; see SessionStore.
+func (r *SessionRepository) DeleteByID(id, userID string) error {
+	result := r.db.Delete(&model.Session{}, "id = ? AND user_id = ?", id, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
 }
 
 func (r *SessionRepository) DeleteByUserID(userID string) error {
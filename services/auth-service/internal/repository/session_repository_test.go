@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/crypto/kms"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	// model.Session's User association carries `serializer:envelope`
+	// fields (TOTPSecret); AutoMigrate touches them even though this
+	// file's tests never do, so the serializer must be registered once
+	// here the same way cmd/server/main.go does at startup.
+	keyProvider, err := kms.NewLocalKeyProvider("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	if err != nil {
+		panic(err)
+	}
+	kms.RegisterGormSerializer(kms.NewEnvelopeCipher(keyProvider))
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.Session{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// TestRotateTokenFamilyConcurrentReuse pins down the fix for the race where
+// two callers presenting the same still-current refresh token could both
+// read CurrentTokenHash before either wrote back a rotation, and both walk
+// away believing they'd rotated successfully. With the conditional
+// UPDATE+RowsAffected check, exactly one of two concurrent rotations
+// against the same presented hash must succeed and the other must report
+// reuse.
+func TestRotateTokenFamilyConcurrentReuse(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewSessionRepository(db)
+
+	familyID := "family-1"
+	currentHash := "hash-v1"
+	session := &model.Session{
+		UserID:           "user-1",
+		TokenFamilyID:    familyID,
+		CurrentTokenHash: currentHash,
+		ExpiresAt:        time.Now().Add(time.Hour),
+	}
+	if err := repo.Create(session); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	const attempts = 10
+	var wg sync.WaitGroup
+	successes := make([]bool, attempts)
+	reusedFlags := make([]bool, attempts)
+	errs := make([]error, attempts)
+
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, reused, err := repo.RotateTokenFamily(familyID, currentHash, "hash-v2-from-goroutine", time.Now().Add(time.Hour))
+			reusedFlags[i] = reused
+			errs[i] = err
+			successes[i] = err == nil && !reused
+		}(i)
+	}
+	wg.Wait()
+
+	successCount := 0
+	for i := 0; i < attempts; i++ {
+		if successes[i] {
+			successCount++
+		}
+	}
+	if successCount != 1 {
+		t.Errorf("got %d successful rotations racing on the same presented token, want exactly 1 (others should detect reuse)", successCount)
+	}
+}
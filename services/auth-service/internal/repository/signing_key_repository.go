@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"gorm.io/gorm"
+)
+
+var ErrSigningKeyNotFound = errors.New("signing key not found")
+
+type SigningKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) *SigningKeyRepository {
+	return &SigningKeyRepository{db: db}
+}
+
+func (r *SigningKeyRepository) Create(key *model.SigningKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *SigningKeyRepository) FindByKid(kid string) (*model.SigningKey, error) {
+	var key model.SigningKey
+	result := r.db.Where("kid = ?", kid).First(&key)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrSigningKeyNotFound
+		}
+		return nil, result.Error
+	}
+	return &key, nil
+}
+
+// FindActive returns the current signing key (status = active), used to
+// sign new tokens. Prior keys stay in FindPublishable for verification.
+func (r *SigningKeyRepository) FindActive() (*model.SigningKey, error) {
+	var key model.SigningKey
+	result := r.db.Where("status = ?", "active").Order("created_at DESC").First(&key)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrSigningKeyNotFound
+		}
+		return nil, result.Error
+	}
+	return &key, nil
+}
+
+// FindPublishable returns every key still valid for verification (active or
+// retired-but-not-yet-expired), for the JWKS endpoint. A revoked key's
+// NotAfter is set to the moment it was revoked, dropping it immediately.
+func (r *SigningKeyRepository) FindPublishable() ([]model.SigningKey, error) {
+	var keys []model.SigningKey
+	result := r.db.Where("status IN ? AND (not_after IS NULL OR not_after > ?)",
+		[]string{"active", "rotating", "retired"}, r.db.NowFunc()).Order("created_at DESC").Find(&keys)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return keys, nil
+}
+
+func (r *SigningKeyRepository) Update(key *model.SigningKey) error {
+	return r.db.Save(key).Error
+}
+
+func (r *SigningKeyRepository) RetireAllActive() error {
+	return r.db.Model(&model.SigningKey{}).Where("status = ?", "active").Update("status", "retired").Error
+}
@@ -0,0 +1,21 @@
+package repository
+
+import "github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+
+// UserStore is the storage contract every user-identity backend must
+// satisfy. *UserRepository (GORM/Postgres) is the built-in implementation;
+// operators can instead point the service at a subprocess implementing the
+// same contract over gRPC — see plugin/userstorage and plugins/bolt-user-storage.
+type UserStore interface {
+	Create(user *model.User) error
+	FindByID(id string) (*model.User, error)
+	FindByEmail(email string) (*model.User, error)
+	FindByUsername(username string) (*model.User, error)
+	FindByExternalID(externalID string) (*model.User, error)
+	FindByProviderID(provider model.AuthProvider, providerID string) (*model.User, error)
+	Update(user *model.User) error
+	Delete(id string) error
+	List(page, perPage int) ([]model.User, int64, error)
+}
+
+var _ UserStore = (*UserRepository)(nil)
@@ -0,0 +1,97 @@
+// Package scope implements parsing and matching for space-delimited OAuth
+// scope strings (RFC 6749 section 3.3), mapping requested scopes like
+// "openid profile resume:read" to claim inclusion and downscoping.
+package scope
+
+import "strings"
+
+// Set is a parsed collection of scopes, deduplicated.
+type Set map[string]struct{}
+
+// Parse splits a space-delimited scope string into a Set.
+func Parse(raw string) Set {
+	set := make(Set)
+	for _, s := range strings.Fields(raw) {
+		set[s] = struct{}{}
+	}
+	return set
+}
+
+// FromSlice builds a Set from already-split scope strings, e.g. the
+// []string a DomainAccessToken persists scopes as.
+func FromSlice(scopes []string) Set {
+	set := make(Set, len(scopes))
+	for _, s := range scopes {
+		set[s] = struct{}{}
+	}
+	return set
+}
+
+// String renders the set back to the RFC 6749 wire format, sorted for
+// deterministic output.
+func (s Set) String() string {
+	parts := make([]string, 0, len(s))
+	for scope := range s {
+		parts = append(parts, scope)
+	}
+	// Simple insertion sort keeps this dependency-free for a handful of scopes.
+	for i := 1; i < len(parts); i++ {
+		for j := i; j > 0 && parts[j-1] > parts[j]; j-- {
+			parts[j-1], parts[j] = parts[j], parts[j-1]
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Has reports whether the set authorizes scope, either exactly or via a
+// hierarchical wildcard grant: holding "notes:*" satisfies a request for
+// "notes:read", and "admin:*" satisfies "admin:oauth-config:write". It does
+// not match the other direction (holding "notes:read" does not satisfy a
+// request for "notes:*").
+//
+// Resource-bound scopes (e.g. "share:{shareID}:read", minted when a user
+// creates a public share) are plain literal scopes under this same scheme:
+// they're checked with exact equality like any other scope, just with a
+// resource ID as one of the colon-delimited segments.
+func (s Set) Has(scope string) bool {
+	if _, ok := s[scope]; ok {
+		return true
+	}
+	for granted := range s {
+		if isWildcardPrefixOf(granted, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWildcardPrefixOf reports whether granted is a "*"-suffixed hierarchical
+// scope (e.g. "notes:*") whose prefix namespace covers requested.
+func isWildcardPrefixOf(granted, requested string) bool {
+	if !strings.HasSuffix(granted, ":*") {
+		return false
+	}
+	prefix := strings.TrimSuffix(granted, "*")
+	return strings.HasPrefix(requested, prefix)
+}
+
+// Intersect returns the subset of requested that also appears in granted,
+// used to downscope a token request against what the user/client actually
+// has authority over.
+func Intersect(granted, requested Set) Set {
+	result := make(Set)
+	for s := range requested {
+		if granted.Has(s) {
+			result[s] = struct{}{}
+		}
+	}
+	return result
+}
+
+// KnownScopes maps standard OIDC scopes to the claims they expose. Used by
+// the userinfo/id_token claim-inclusion logic.
+var KnownScopes = map[string][]string{
+	"openid":  {"sub"},
+	"profile": {"name", "username", "picture"},
+	"email":   {"email", "email_verified"},
+}
@@ -0,0 +1,112 @@
+package scope
+
+import "testing"
+
+func TestHasExactMatch(t *testing.T) {
+	set := Parse("openid profile notes:read")
+
+	if !set.Has("notes:read") {
+		t.Errorf("Has(%q) = false, want true", "notes:read")
+	}
+	if set.Has("notes:write") {
+		t.Errorf("Has(%q) = true, want false", "notes:write")
+	}
+}
+
+func TestHasWildcard(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted string
+		request string
+		want    bool
+	}{
+		{"wildcard covers one-level child", "notes:*", "notes:read", true},
+		{"wildcard covers multi-segment child", "admin:*", "admin:oauth-config:write", true},
+		{"wildcard does not cover sibling namespace", "notes:*", "share:read", false},
+		{"wildcard does not match in reverse", "notes:read", "notes:*", false},
+		{"exact literal still matches without wildcard", "notes:read", "notes:read", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := Parse(tt.granted)
+			if got := set.Has(tt.request); got != tt.want {
+				t.Errorf("Parse(%q).Has(%q) = %v, want %v", tt.granted, tt.request, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasResourceBoundScope(t *testing.T) {
+	set := Parse("share:abc123:read")
+
+	if !set.Has("share:abc123:read") {
+		t.Error("Has on the exact resource-bound scope = false, want true")
+	}
+	if set.Has("share:other:read") {
+		t.Error("Has on a different resource ID = true, want false")
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	tests := []struct {
+		name      string
+		granted   string
+		requested string
+		want      string
+	}{
+		{"requested subset of granted", "notes:read notes:write profile", "notes:read profile", "notes:read profile"},
+		{"requested covered via wildcard", "notes:*", "notes:read notes:write", "notes:read notes:write"},
+		{"requested scope not granted is dropped", "notes:read", "notes:read notes:write", "notes:read"},
+		{"nothing granted yields empty set", "", "notes:read", ""},
+		{"nothing requested yields empty set", "notes:read", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Intersect(Parse(tt.granted), Parse(tt.requested))
+			if got.String() != tt.want {
+				t.Errorf("Intersect(%q, %q) = %q, want %q", tt.granted, tt.requested, got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestIntersectIsNotSymmetric(t *testing.T) {
+	// A wildcard grant intersects down to the requested literal scopes, not
+	// the wildcard itself - Intersect(requested, granted) would instead keep
+	// only what's literally present in the narrower requested set.
+	granted := Parse("notes:*")
+	requested := Parse("notes:read")
+
+	if got := Intersect(granted, requested).String(); got != "notes:read" {
+		t.Errorf("Intersect(granted, requested) = %q, want %q", got, "notes:read")
+	}
+	if got := Intersect(requested, granted).String(); got != "" {
+		t.Errorf("Intersect(requested, granted) = %q, want empty (requested doesn't grant notes:*)", got)
+	}
+}
+
+func TestParseDeduplicates(t *testing.T) {
+	set := Parse("openid openid profile")
+	if len(set) != 2 {
+		t.Errorf("len(Parse(%q)) = %d, want 2", "openid openid profile", len(set))
+	}
+}
+
+func TestFromSlice(t *testing.T) {
+	set := FromSlice([]string{"notes:read", "notes:read", "share:read"})
+	if len(set) != 2 {
+		t.Errorf("len(FromSlice(...)) = %d, want 2", len(set))
+	}
+	if !set.Has("share:read") {
+		t.Error("FromSlice set missing share:read")
+	}
+}
+
+func TestStringIsSortedAndSpaceDelimited(t *testing.T) {
+	set := Parse("profile openid email")
+	if got, want := set.String(), "email openid profile"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -1,39 +1,202 @@
 package service
 
 import (
+	"context"
+	"crypto/sha256"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/audit"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/cache"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/config"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/oidc"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/repository"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/utils"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // bcrypt cost as per SECURITY.md policy (12 rounds)
 const bcryptCost = 12
 
+// Token types beyond the usual access/refresh pair.
+const (
+	tokenTypeMFAPending = "mfa_pending"
+	tokenTypeStepUp     = "step_up"
+
+	mfaPendingTokenExpiry = 5 * time.Minute
+	stepUpTokenExpiry     = 5 * time.Minute
+)
+
+// Failed-login lockout thresholds, mirroring MFAService's attempt counter.
+const (
+	loginMaxAttempts    = 5
+	loginAttemptLockout = 15 * time.Minute
+)
+
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrInvalidToken       = errors.New("invalid token")
 	ErrTokenExpired       = errors.New("token expired")
 	ErrUserNotActive      = errors.New("user is not active")
+	// ErrTokenReplayed is returned when a refresh token that has already
+	// been rotated past is presented again. The whole token family has been
+	// revoked by the time this is returned; the caller must log in again.
+	ErrTokenReplayed = errors.New("refresh token reuse detected")
+	// ErrProviderDisabled is returned by Register/Login when the requested
+	// model.AuthProvider has no registered LoginProvider or has been
+	// disabled via AuthProviderConfig - including "local" itself, which is
+	// what puts the service into SSO-only mode.
+	ErrProviderDisabled = errors.New("authentication provider is disabled")
+	// ErrAccountLocked is returned by Login when an account has hit
+	// loginMaxAttempts failed attempts within loginAttemptLockout. Call
+	// LoginLockout to get the remaining duration to surface as retryAfter.
+	ErrAccountLocked = errors.New("too many failed login attempts")
+	// ErrOAuthEmailNotVerified is returned by FindOrCreateOAuthUser when an
+	// upstream identity would auto-link to an existing local account by
+	// email match alone, but the provider didn't assert email_verified.
+	// Without this, anyone who registers on a lower-trust upstream IdP with
+	// a victim's self-reported email takes over the victim's local
+	// account. Callers should surface this as a normal login failure, not
+	// silently fall through to account creation.
+	ErrOAuthEmailNotVerified = errors.New("oauth provider did not verify email ownership")
 )
 
 type AuthService struct {
-	cfg         *config.Config
-	userRepo    *repository.UserRepository
-	sessionRepo *repository.SessionRepository
+	cfg                *config.Config
+	userRepo           repository.UserStore
+	sessionRepo        repository.SessionStore
+	auditLog           *audit.Logger
+	keyManager         *oidc.KeyManager
+	providerConfigRepo *repository.AuthProviderConfigRepository
+	loginProviders     map[model.AuthProvider]LoginProvider
+
+	// loginAttemptCache holds the per-email failure count/lockout, backed
+	// by the same cache.Cache that fronts session/domain-access lookups
+	// (see cached_session_repository.go) so an entry's TTL bounds it
+	// instead of accumulating in memory for the life of the process.
+	loginAttemptCache cache.Cache
+}
+
+// NewAuthService wires keyManager so access tokens can be verified by other
+// services off the public JWKS instead of a shared HMAC secret; see
+// generateAccessToken. mfa_pending and step_up tokens stay HMAC-signed since
+// they're short-lived and only ever consumed by auth-service itself.
+//
+// sessionRepo is a repository.SessionStore rather than the concrete
+// *repository.SessionRepository so cmd/server can front it with
+// repository.CachedSessionRepository when CACHE_BACKEND is configured.
+//
+// providerConfigRepo backs isProviderEnabled so Login/Register can refuse a
+// provider an admin has disabled via AuthProviderConfig. The "local" and
+// "ldap" LoginProvider implementations are registered here; cfg.LDAP.URL
+// being blank leaves "ldap" unregistered, matching the out-of-the-box
+// local-only default.
+//
+// loginAttemptCache is the same cache.Cache instance cmd/server fronts
+// sessionRepo/domainAccessRepo with, reused here for the failed-login
+// lockout counter.
+func NewAuthService(cfg *config.Config, userRepo repository.UserStore, sessionRepo repository.SessionStore, auditLog *audit.Logger, keyManager *oidc.KeyManager, providerConfigRepo *repository.AuthProviderConfigRepository, loginAttemptCache cache.Cache) *AuthService {
+	s := &AuthService{
+		cfg:                cfg,
+		userRepo:           userRepo,
+		sessionRepo:        sessionRepo,
+		auditLog:           auditLog,
+		keyManager:         keyManager,
+		providerConfigRepo: providerConfigRepo,
+		loginAttemptCache:  loginAttemptCache,
+	}
+
+	s.loginProviders = map[model.AuthProvider]LoginProvider{
+		model.ProviderLocal: &localLoginProvider{userRepo: userRepo},
+	}
+	if cfg.LDAP.URL != "" {
+		s.loginProviders[model.ProviderLDAP] = newLDAPLoginProvider(cfg.LDAP, s)
+	}
+
+	return s
+}
+
+// isProviderEnabled reports whether provider may be used to log in. A
+// missing AuthProviderConfig row means "never configured"; "local" stays
+// enabled in that case so the service doesn't lock itself out before an
+// admin has toggled anything, while every other provider defaults to
+// disabled until explicitly turned on (matching ToggleProvider's
+// create-on-first-toggle behavior).
+func (s *AuthService) isProviderEnabled(provider model.AuthProvider) (bool, error) {
+	providerConfig, err := s.providerConfigRepo.FindByProvider(provider)
+	if err != nil {
+		if errors.Is(err, repository.ErrAuthProviderConfigNotFound) {
+			return provider == model.ProviderLocal, nil
+		}
+		return false, err
+	}
+	return providerConfig.Enabled, nil
+}
+
+// loginLockKey normalizes email so lockout can't be bypassed by case alone.
+func loginLockKey(email string) string {
+	return "login_attempts:" + strings.ToLower(email)
+}
+
+// loginLockedKey namespaces the lockout deadline set once loginLockKey's
+// counter crosses loginMaxAttempts, mirroring MFAService's lockKey.
+func loginLockedKey(email string) string {
+	return "login_locked:" + strings.ToLower(email)
 }
 
-func NewAuthService(cfg *config.Config, userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository) *AuthService {
-	return &AuthService{
-		cfg:         cfg,
-		userRepo:    userRepo,
-		sessionRepo: sessionRepo,
+// isLoginLocked reports whether email has hit loginMaxAttempts failures
+// within the current lockout window, mirroring MFAService.isLocked.
+func (s *AuthService) isLoginLocked(email string) (bool, time.Duration) {
+	cached, found, err := s.loginAttemptCache.Get(context.Background(), loginLockedKey(email))
+	if err != nil || !found {
+		return false, 0
+	}
+	lockedUntil, err := time.Parse(time.RFC3339Nano, cached)
+	if err != nil || time.Now().After(lockedUntil) {
+		return false, 0
 	}
+	return true, time.Until(lockedUntil)
+}
+
+// LoginLockout is the handler-facing equivalent of isLoginLocked, used to
+// compute the retryAfter field on a 423 response after Login has already
+// returned ErrAccountLocked.
+func (s *AuthService) LoginLockout(email string) (locked bool, retryAfter time.Duration) {
+	return s.isLoginLocked(email)
+}
+
+// recordLoginFailure atomically increments email's failure count and, the
+// moment it reaches loginMaxAttempts, sets the lockout deadline. Using
+// Increment rather than a Get-then-Set round trip means two concurrent
+// failed attempts can't both read the same pre-increment count and both
+// walk away thinking they're one failure short of a lockout.
+func (s *AuthService) recordLoginFailure(email string) {
+	ctx := context.Background()
+	n, err := s.loginAttemptCache.Increment(ctx, loginLockKey(email), loginAttemptLockout)
+	if err != nil || n < loginMaxAttempts {
+		return
+	}
+	lockedUntil := time.Now().Add(loginAttemptLockout)
+	s.loginAttemptCache.Set(ctx, loginLockedKey(email), lockedUntil.Format(time.RFC3339Nano), loginAttemptLockout)
+	s.loginAttemptCache.Delete(ctx, loginLockKey(email))
+}
+
+func (s *AuthService) resetLoginAttempts(email string) {
+	ctx := context.Background()
+	s.loginAttemptCache.Delete(ctx, loginLockKey(email))
+	s.loginAttemptCache.Delete(ctx, loginLockedKey(email))
+}
+
+// CnfClaim is the RFC 9449 "cnf" confirmation claim binding an access token
+// to the DPoP key whose thumbprint minted it. Absent for tokens issued
+// without a DPoP proof.
+type CnfClaim struct {
+	Jkt string `json:"jkt"`
 }
 
 type Claims struct {
@@ -43,11 +206,48 @@ type Claims struct {
 	Role     model.Role         `json:"role"`
 	Type     string             `json:"type"`
 	Provider model.AuthProvider `json:"provider,omitempty"`
+	// Scope is space-delimited per RFC 6749 section 3.3. Session tokens
+	// carry the scope implied by the user's role (see scopesForRole); the
+	// OAuth2/OIDC authorization-code flow in internal/oidc downscopes
+	// against a client's own granted scopes instead.
+	Scope string    `json:"scope,omitempty"`
+	Cnf   *CnfClaim `json:"cnf,omitempty"`
+	// AuthTime is the OIDC-style auth_time claim: when the subject actually
+	// authenticated (login or MFA verification), not when this particular
+	// access token was minted. A refreshed access token carries forward the
+	// auth_time of the session it belongs to rather than resetting it, so
+	// middleware.RequireRecentAuth can't be fooled by refreshing a stale
+	// session right before a sensitive action.
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
 }
 
-func (s *AuthService) Register(req *model.RegisterRequest) (*model.AuthResponse, error) {
+// scopesForRole is the baseline authority a session access token carries for
+// each role, enforced via middleware.ScopeMiddleware on endpoints whose
+// authorization is better expressed as a scope than a coarse role check.
+func scopesForRole(role model.Role) string {
+	switch role {
+	case model.RoleMaster:
+		return "profile email admin:*"
+	case model.RoleManager:
+		return "profile email admin:audit:read admin:oauth-config:write"
+	default:
+		return "profile email"
+	}
+}
+
+func (s *AuthService) Register(req *model.RegisterRequest, userAgent, ip, dpopThumbprint string) (*model.AuthResponse, error) {
+	// Local registration is refused outright once an admin has disabled the
+	// "local" AuthProviderConfig row, putting the service into SSO-only mode.
+	localEnabled, err := s.isProviderEnabled(model.ProviderLocal)
+	if err != nil {
+		return nil, err
+	}
+	if !localEnabled {
+		return nil, ErrProviderDisabled
+	}
+
 	// Check if user already exists by email
-	_, err := s.userRepo.FindByEmail(req.Email)
+	_, err = s.userRepo.FindByEmail(req.Email)
 	if err == nil {
 		return nil, repository.ErrUserAlreadyExists
 	}
@@ -95,23 +295,65 @@ func (s *AuthService) Register(req *model.RegisterRequest) (*model.AuthResponse,
 		return nil, err
 	}
 
+	s.auditLog.Log(audit.ActionRegister, user.ID, user.ID, ip, userAgent, nil)
+
 	// Generate tokens
-	return s.generateAuthResponse(user, "", "")
+	return s.generateAuthResponse(user, userAgent, ip, dpopThumbprint)
+}
+
+// LoginResult is returned from Login. Exactly one of AuthResponse or
+// MFAChallenge is populated: accounts with TOTP enabled get a challenge
+// that must be exchanged via VerifyMFA before a real session is issued.
+type LoginResult struct {
+	AuthResponse *model.AuthResponse
+	MFAChallenge *model.MFAChallengeResponse
 }
 
-func (s *AuthService) Login(req *model.LoginRequest, userAgent, ip string) (*model.AuthResponse, error) {
-	user, err := s.userRepo.FindByEmail(req.Email)
+func (s *AuthService) Login(req *model.LoginRequest, userAgent, ip, dpopThumbprint string) (*LoginResult, error) {
+	provider := model.ProviderLocal
+	if req.Provider != "" {
+		provider = model.AuthProvider(strings.ToUpper(req.Provider))
+	}
+
+	loginProvider, registered := s.loginProviders[provider]
+	enabled, err := s.isProviderEnabled(provider)
 	if err != nil {
-		return nil, ErrInvalidCredentials
+		return nil, err
+	}
+	if !registered || !enabled {
+		return nil, ErrProviderDisabled
+	}
+
+	if locked, _ := s.isLoginLocked(req.Email); locked {
+		s.auditLog.Log(audit.ActionLoginFailure, "", "", ip, userAgent, map[string]any{"email": req.Email, "provider": provider, "reason": "locked"})
+		return nil, ErrAccountLocked
+	}
+
+	user, err := loginProvider.AttemptLogin(context.Background(), req.Email, req.Password)
+	if err != nil {
+		s.recordLoginFailure(req.Email)
+		s.auditLog.Log(audit.ActionLoginFailure, "", "", ip, userAgent, map[string]any{"email": req.Email, "provider": provider, "reason": err.Error()})
+		return nil, err
 	}
 
 	if !user.IsActive {
 		return nil, ErrUserNotActive
 	}
 
-	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return nil, ErrInvalidCredentials
+	s.resetLoginAttempts(req.Email)
+
+	if user.TOTPEnabled {
+		mfaToken, err := s.generateMFAPendingToken(user)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{
+			MFAChallenge: &model.MFAChallengeResponse{
+				MFARequired: true,
+				MFAToken:    mfaToken,
+				ExpiresIn:   int64(mfaPendingTokenExpiry.Seconds()),
+			},
+		}, nil
 	}
 
 	// Update last login
@@ -119,68 +361,176 @@ func (s *AuthService) Login(req *model.LoginRequest, userAgent, ip string) (*mod
 	user.LastLoginAt = &now
 	s.userRepo.Update(user)
 
-	return s.generateAuthResponse(user, userAgent, ip)
+	authResp, err := s.generateAuthResponse(user, userAgent, ip, dpopThumbprint)
+	if err != nil {
+		return nil, err
+	}
+	s.auditLog.Log(audit.ActionLoginSuccess, user.ID, user.ID, ip, userAgent, nil)
+	return &LoginResult{AuthResponse: authResp}, nil
 }
 
-func (s *AuthService) RefreshToken(refreshToken string) (*model.TokenResponse, error) {
-	// Validate refresh token
-	claims, err := s.validateToken(refreshToken)
+// VerifyMFA exchanges a valid mfa_pending token plus a TOTP or recovery code
+// for a real access/refresh token pair, completing the step-up login flow.
+func (s *AuthService) VerifyMFA(mfaToken, code string, mfaSvc *MFAService, userAgent, ip, dpopThumbprint string) (*model.AuthResponse, error) {
+	claims, err := s.validateToken(mfaToken)
 	if err != nil {
 		return nil, err
 	}
-
-	if claims.Type != "refresh" {
+	if claims.Type != tokenTypeMFAPending {
 		return nil, ErrInvalidToken
 	}
 
-	// Find session
-	session, err := s.sessionRepo.FindByRefreshToken(refreshToken)
+	user, err := s.userRepo.FindByID(claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrUserNotActive
+	}
+
+	ok, err := mfaSvc.VerifyCodeOrRecovery(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.auditLog.Log(audit.ActionLoginFailure, user.ID, user.ID, ip, userAgent, map[string]any{"stage": "mfa"})
+		return nil, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	user.LastLoginAt = &now
+	s.userRepo.Update(user)
+
+	authResp, err := s.generateAuthResponse(user, userAgent, ip, dpopThumbprint)
+	if err != nil {
+		return nil, err
+	}
+	s.auditLog.Log(audit.ActionLoginSuccess, user.ID, user.ID, ip, userAgent, nil)
+	return authResp, nil
+}
+
+// Reauthenticate verifies the user's current password (or TOTP code, for
+// MFA-enabled accounts) and issues a short-lived step_up token used to gate
+// sensitive actions such as password changes and MFA disablement.
+func (s *AuthService) Reauthenticate(userID, password, totpCode string, mfaSvc *MFAService) (*model.StepUpResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	verified := false
+	if user.TOTPEnabled && totpCode != "" {
+		ok, err := mfaSvc.VerifyCodeOrRecovery(user, totpCode)
+		if err != nil {
+			return nil, err
+		}
+		verified = ok
+	} else if user.Provider == model.ProviderLocal && password != "" {
+		verified = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) == nil
+	}
+
+	if !verified {
+		return nil, ErrInvalidCredentials
+	}
+
+	token, err := s.generateStepUpToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.StepUpResponse{
+		StepUpToken: token,
+		ExpiresIn:   int64(stepUpTokenExpiry.Seconds()),
+	}, nil
+}
+
+func (s *AuthService) RefreshToken(refreshToken, userAgent, ip, dpopThumbprint string) (*model.TokenResponse, error) {
+	familyID, err := tokenFamilyID(refreshToken)
 	if err != nil {
 		return nil, ErrInvalidToken
 	}
 
-	// Get user
-	user, err := s.userRepo.FindByID(session.UserID)
+	newToken, newHash, err := s.generateOpaqueRefreshToken(familyID)
 	if err != nil {
 		return nil, err
 	}
 
-	if !user.IsActive {
-		return nil, ErrUserNotActive
+	session, reused, err := s.sessionRepo.RotateTokenFamily(
+		familyID,
+		hashRefreshToken(refreshToken),
+		newHash,
+		time.Now().Add(s.cfg.JWT.RefreshTokenExpiry),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if reused {
+		// The presented token was already superseded by a later rotation:
+		// someone else has this family's refresh token. Kill the whole
+		// family rather than just this request (OAuth 2.1 section 4.14).
+		s.auditLog.Log(audit.ActionTokenReuse, session.UserID, session.UserID, ip, userAgent, nil)
+		return nil, ErrTokenReplayed
 	}
 
-	// Generate new access token
-	accessToken, err := s.generateAccessToken(user)
+	user, err := s.userRepo.FindByID(session.UserID)
 	if err != nil {
 		return nil, err
 	}
+	if !user.IsActive {
+		return nil, ErrUserNotActive
+	}
 
-	// Generate new refresh token
-	newRefreshToken, err := s.generateRefreshToken(user)
+	// The session row is updated in place on rotation, not recreated, so its
+	// CreatedAt is the original login time - carry that forward as auth_time
+	// rather than resetting it to now, or a stale session could renew itself
+	// past RequireRecentAuth's maxAge just by refreshing.
+	accessToken, err := s.generateAccessToken(user, dpopThumbprint, session.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 
-	// Update session
-	session.RefreshToken = newRefreshToken
-	session.ExpiresAt = time.Now().Add(s.cfg.JWT.RefreshTokenExpiry)
-	s.sessionRepo.Update(session)
+	s.auditLog.Log(audit.ActionRefresh, user.ID, user.ID, ip, userAgent, nil)
 
 	return &model.TokenResponse{
 		AccessToken:  accessToken,
-		RefreshToken: newRefreshToken,
+		RefreshToken: newToken,
 		ExpiresIn:    int64(s.cfg.JWT.AccessTokenExpiry.Seconds()),
 	}, nil
 }
 
-func (s *AuthService) Logout(refreshToken string) error {
-	return s.sessionRepo.DeleteByRefreshToken(refreshToken)
+func (s *AuthService) Logout(refreshToken, userAgent, ip string) error {
+	familyID, err := tokenFamilyID(refreshToken)
+	if err != nil {
+		return nil
+	}
+
+	session, err := s.sessionRepo.FindByTokenFamilyID(familyID)
+	if err == nil {
+		s.auditLog.Log(audit.ActionLogout, session.UserID, session.UserID, ip, userAgent, nil)
+	}
+	return s.sessionRepo.DeleteByTokenFamilyID(familyID)
 }
 
 func (s *AuthService) GetUserByID(id string) (*model.User, error) {
 	return s.userRepo.FindByID(id)
 }
 
+// ListSessions returns userID's active refresh-token sessions for
+// GET /v1/users/me/sessions.
+func (s *AuthService) ListSessions(userID string) ([]model.Session, error) {
+	return s.sessionRepo.FindByUserID(userID)
+}
+
+// RevokeSession revokes a single session owned by userID, forcing that
+// device to re-login next time its refresh token is used.
+func (s *AuthService) RevokeSession(id, userID, userAgent, ip string) error {
+	if err := s.sessionRepo.DeleteByID(id, userID); err != nil {
+		return err
+	}
+	s.auditLog.Log(audit.ActionTokenRevoke, userID, userID, ip, userAgent, map[string]any{"sessionId": id})
+	return nil
+}
+
 func (s *AuthService) ValidateAccessToken(tokenString string) (*Claims, error) {
 	claims, err := s.validateToken(tokenString)
 	if err != nil {
@@ -194,24 +544,26 @@ func (s *AuthService) ValidateAccessToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
-func (s *AuthService) generateAuthResponse(user *model.User, userAgent, ip string) (*model.AuthResponse, error) {
-	accessToken, err := s.generateAccessToken(user)
+func (s *AuthService) generateAuthResponse(user *model.User, userAgent, ip, dpopThumbprint string) (*model.AuthResponse, error) {
+	now := time.Now()
+	accessToken, err := s.generateAccessToken(user, dpopThumbprint, now)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := s.generateRefreshToken(user)
+	refreshToken, hash, err := s.generateOpaqueRefreshToken("")
 	if err != nil {
 		return nil, err
 	}
 
-	// Create session
+	familyID, _ := tokenFamilyID(refreshToken)
 	session := &model.Session{
-		UserID:       user.ID,
-		RefreshToken: refreshToken,
-		UserAgent:    userAgent,
-		IP:           ip,
-		ExpiresAt:    time.Now().Add(s.cfg.JWT.RefreshTokenExpiry),
+		UserID:           user.ID,
+		TokenFamilyID:    familyID,
+		CurrentTokenHash: hash,
+		UserAgent:        userAgent,
+		IP:               ip,
+		ExpiresAt:        time.Now().Add(s.cfg.JWT.RefreshTokenExpiry),
 	}
 	s.sessionRepo.Create(session)
 
@@ -223,7 +575,20 @@ func (s *AuthService) generateAuthResponse(user *model.User, userAgent, ip strin
 	}, nil
 }
 
-func (s *AuthService) generateAccessToken(user *model.User) (string, error) {
+// generateAccessToken signs a short-lived access token. When dpopThumbprint
+// is non-empty the token carries a cnf.jkt claim binding it to that DPoP
+// key (RFC 9449); AuthMiddleware then requires a matching proof on every
+// request that presents it. authTime is the moment the user actually
+// authenticated - the login/MFA timestamp for a fresh session, or the
+// originating session's CreatedAt when called from RefreshToken - and is
+// carried in the auth_time claim for middleware.RequireRecentAuth.
+//
+// The token is signed RS256 with the shared oidc.KeyManager's active key
+// (the same one published at /.well-known/jwks.json) so other services can
+// verify it against the public JWKS without holding JWT.Secret; the kid
+// header tells the verifier which published key to use. Falls back to the
+// legacy HS256 signing only if keyManager is nil.
+func (s *AuthService) generateAccessToken(user *model.User, dpopThumbprint string, authTime time.Time) (string, error) {
 	now := time.Now()
 	claims := &Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -236,29 +601,103 @@ func (s *AuthService) generateAccessToken(user *model.User) (string, error) {
 		Role:     user.Role,
 		Type:     "access",
 		Provider: user.Provider,
+		Scope:    scopesForRole(user.Role),
+		AuthTime: jwt.NewNumericDate(authTime),
+	}
+	if dpopThumbprint != "" {
+		claims.Cnf = &CnfClaim{Jkt: dpopThumbprint}
+	}
+
+	if s.keyManager == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(s.cfg.JWT.Secret))
+	}
+
+	priv, kid, err := s.keyManager.ActiveKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(priv)
+}
+
+// generateOpaqueRefreshToken mints a new refresh token of the form
+// "<familyID>.<secret>". familyID is carried over on rotation (pass the
+// family ID parsed from the token being refreshed); pass "" to start a new
+// family at login. Only the returned hash is ever persisted.
+func (s *AuthService) generateOpaqueRefreshToken(familyID string) (token, hash string, err error) {
+	if familyID == "" {
+		familyID = uuid.New().String()
+	}
+	secret, err := utils.GenerateRandomBase62(40)
+	if err != nil {
+		return "", "", err
+	}
+	token = familyID + "." + secret
+	return token, hashRefreshToken(token), nil
+}
+
+// tokenFamilyID extracts the family ID prefix from an opaque refresh token.
+func tokenFamilyID(refreshToken string) (string, error) {
+	parts := strings.SplitN(refreshToken, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", ErrInvalidToken
+	}
+	return parts[0], nil
+}
+
+func hashRefreshToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return fmt.Sprintf("%x", sum)
+}
+
+func (s *AuthService) generateMFAPendingToken(user *model.User) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(mfaPendingTokenExpiry)),
+		},
+		Email: user.Email,
+		Type:  tokenTypeMFAPending,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(s.cfg.JWT.Secret))
 }
 
-func (s *AuthService) generateRefreshToken(user *model.User) (string, error) {
+func (s *AuthService) generateStepUpToken(user *model.User) (string, error) {
 	now := time.Now()
 	claims := &Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   user.ID,
 			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(s.cfg.JWT.RefreshTokenExpiry)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(stepUpTokenExpiry)),
 		},
 		Email: user.Email,
-		Role:  user.Role,
-		Type:  "refresh",
+		Type:  tokenTypeStepUp,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(s.cfg.JWT.Secret))
 }
 
+// ValidateStepUpToken validates a step_up token minted by Reauthenticate and
+// returns the user ID it was issued for. Satisfies middleware.StepUpResolver,
+// used by RequireRecentAuth to gate sensitive handlers.
+func (s *AuthService) ValidateStepUpToken(tokenString string) (string, error) {
+	claims, err := s.validateToken(tokenString)
+	if err != nil {
+		return "", err
+	}
+	if claims.Type != tokenTypeStepUp {
+		return "", ErrInvalidToken
+	}
+	return claims.Subject, nil
+}
+
 func (s *AuthService) validateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -283,11 +722,23 @@ func (s *AuthService) validateToken(tokenString string) (*Claims, error) {
 }
 
 // OAuth methods
-func (s *AuthService) FindOrCreateOAuthUser(provider model.AuthProvider, providerID, email, name, picture string) (*model.User, error) {
+// FindOrCreateOAuthUser finds or creates the local user for an upstream
+// OAuth login. role is the role resolved from the provider's group/role
+// claim mapping (see oauthupstream.ResolveRole); an empty role leaves an
+// existing user's role untouched and defaults a new user to RoleUser.
+// emailVerified reports whether the provider itself asserted email
+// ownership (its "email_verified" claim); it gates the email-match account
+// link below, since that link grants upstream-controlled sign-in to
+// whatever local account holds that address.
+func (s *AuthService) FindOrCreateOAuthUser(provider model.AuthProvider, providerID, email, name, picture, role string, emailVerified bool, userAgent, ip string) (*model.User, error) {
 	// Try to find existing user by provider + providerID
 	user, err := s.userRepo.FindByProviderID(provider, providerID)
 	if err == nil {
-		// Update user info
+		// Update user info. Role is intentionally left alone here: it was
+		// already resolved once, at the link below (or at creation), and
+		// re-applying the IdP's group claim on every subsequent login
+		// would silently overwrite a role an admin later set locally via
+		// PATCH /users/:id.
 		user.Name = name
 		user.Picture = picture
 		now := time.Now()
@@ -296,18 +747,28 @@ func (s *AuthService) FindOrCreateOAuthUser(provider model.AuthProvider, provide
 		return user, nil
 	}
 
-	// Try to find by email and link account
+	// Try to find by email and link account. Only do so when the provider
+	// itself vouches for the address - otherwise anyone who registers on a
+	// lower-trust upstream IdP with a victim's self-reported email would
+	// get auto-linked into the victim's existing local account.
 	user, err = s.userRepo.FindByEmail(email)
 	if err == nil {
+		if !emailVerified {
+			return nil, ErrOAuthEmailNotVerified
+		}
 		// Link OAuth to existing account
 		user.Provider = provider
 		user.ProviderID = providerID
 		user.Name = name
 		user.Picture = picture
 		user.EmailVerified = true
+		if role != "" {
+			user.Role = model.Role(role)
+		}
 		now := time.Now()
 		user.LastLoginAt = &now
 		s.userRepo.Update(user)
+		s.auditLog.Log(audit.ActionOAuthLink, user.ID, user.ID, ip, userAgent, map[string]any{"provider": provider})
 		return user, nil
 	}
 
@@ -329,6 +790,11 @@ func (s *AuthService) FindOrCreateOAuthUser(provider model.AuthProvider, provide
 	// Generate unique username from email
 	username := generateUsername(email)
 
+	newUserRole := model.RoleUser
+	if role != "" {
+		newUserRole = model.Role(role)
+	}
+
 	// Create new user
 	user = &model.User{
 		ExternalID:    externalID,
@@ -336,7 +802,7 @@ func (s *AuthService) FindOrCreateOAuthUser(provider model.AuthProvider, provide
 		Username:      username,
 		Name:          name,
 		Picture:       picture,
-		Role:          model.RoleUser,
+		Role:          newUserRole,
 		Provider:      provider,
 		ProviderID:    providerID,
 		EmailVerified: true,
@@ -350,12 +816,16 @@ func (s *AuthService) FindOrCreateOAuthUser(provider model.AuthProvider, provide
 	return user, nil
 }
 
-func (s *AuthService) GenerateOAuthResponse(user *model.User, userAgent, ip string) (*model.AuthResponse, error) {
-	return s.generateAuthResponse(user, userAgent, ip)
+func (s *AuthService) GenerateOAuthResponse(user *model.User, userAgent, ip, dpopThumbprint string) (*model.AuthResponse, error) {
+	return s.generateAuthResponse(user, userAgent, ip, dpopThumbprint)
 }
 
-// ChangePassword changes user's password
-func (s *AuthService) ChangePassword(userID, currentPassword, newPassword string) error {
+// ChangePassword changes user's password. The caller's current password is
+// no longer checked here: middleware.RequireRecentAuth already gates this
+// handler on a fresh login or step_up token, which is the same proof of
+// ownership a CurrentPassword field would have given and doesn't require
+// OAuth-linked accounts to have one.
+func (s *AuthService) ChangePassword(userID, newPassword, userAgent, ip string) error {
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
 		return err
@@ -366,11 +836,6 @@ func (s *AuthService) ChangePassword(userID, currentPassword, newPassword string
 		return errors.New("password change not allowed for OAuth users")
 	}
 
-	// Verify current password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
-		return ErrInvalidCredentials
-	}
-
 	// Hash new password with 12 rounds
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcryptCost)
 	if err != nil {
@@ -378,17 +843,22 @@ func (s *AuthService) ChangePassword(userID, currentPassword, newPassword string
 	}
 
 	user.PasswordHash = string(hashedPassword)
-	return s.userRepo.Update(user)
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	s.auditLog.Log(audit.ActionPasswordChange, user.ID, user.ID, ip, userAgent, nil)
+	return nil
 }
 
 // GenerateTokens generates access and refresh tokens (public method for OAuth handler)
 func (s *AuthService) GenerateTokens(user *model.User) (*model.TokenResponse, error) {
-	accessToken, err := s.generateAccessToken(user)
+	accessToken, err := s.generateAccessToken(user, "", time.Now())
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := s.generateRefreshToken(user)
+	refreshToken, _, err := s.generateOpaqueRefreshToken("")
 	if err != nil {
 		return nil, err
 	}
@@ -5,15 +5,18 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/audit"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/config"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/repository"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/scope"
 )
 
 var (
-	ErrInvalidDomain     = errors.New("invalid domain")
+	ErrInvalidDomain      = errors.New("invalid domain")
 	ErrDomainAccessDenied = errors.New("domain access denied")
 )
 
@@ -25,24 +28,34 @@ var allowedDomains = map[string]bool{
 
 type DomainAccessService struct {
 	cfg              *config.Config
-	domainAccessRepo *repository.DomainAccessRepository
-	userRepo         *repository.UserRepository
+	domainAccessRepo repository.DomainAccessStore
+	userRepo         repository.UserStore
+	auditLog         *audit.Logger
 }
 
+// domainAccessRepo is a repository.DomainAccessStore rather than the
+// concrete *repository.DomainAccessRepository so cmd/server can front it
+// with repository.CachedDomainAccessRepository when CACHE_BACKEND is
+// configured.
 func NewDomainAccessService(
 	cfg *config.Config,
-	domainAccessRepo *repository.DomainAccessRepository,
-	userRepo *repository.UserRepository,
+	domainAccessRepo repository.DomainAccessStore,
+	userRepo repository.UserStore,
+	auditLog *audit.Logger,
 ) *DomainAccessService {
 	return &DomainAccessService{
 		cfg:              cfg,
 		domainAccessRepo: domainAccessRepo,
 		userRepo:         userRepo,
+		auditLog:         auditLog,
 	}
 }
 
-// GrantAccess creates a new domain access token for sharing
-func (s *DomainAccessService) GrantAccess(userID, domain string, expiresInHours int) (*model.DomainAccessToken, string, error) {
+// GrantAccess creates a new domain access token for sharing. scopes is a
+// space-delimited string per RFC 6749 section 3.3 (e.g.
+// "girok:read girok:write:notes"); empty keeps the legacy all-or-nothing
+// grant.
+func (s *DomainAccessService) GrantAccess(userID, domain string, expiresInHours int, scopes, userAgent, ip string) (*model.DomainAccessToken, string, error) {
 	// Validate domain
 	if !allowedDomains[domain] {
 		return nil, "", ErrInvalidDomain
@@ -63,6 +76,7 @@ func (s *DomainAccessService) GrantAccess(userID, domain string, expiresInHours
 
 	// Calculate expiration
 	expiresAt := time.Now().Add(time.Duration(expiresInHours) * time.Hour)
+	scopeList := strings.Fields(scopes)
 
 	// Check for existing token and update or create
 	existing, err := s.domainAccessRepo.FindByUserAndDomain(userID, domain)
@@ -70,9 +84,11 @@ func (s *DomainAccessService) GrantAccess(userID, domain string, expiresInHours
 		// Update existing token
 		existing.Token = token
 		existing.ExpiresAt = expiresAt
+		existing.Scopes = scopeList
 		if err := s.domainAccessRepo.Update(existing); err != nil {
 			return nil, "", err
 		}
+		s.auditLog.Log(audit.ActionDomainGrant, userID, userID, ip, userAgent, map[string]any{"domain": domain})
 		return existing, s.buildAccessURL(user.ExternalID, domain, token), nil
 	}
 
@@ -82,43 +98,56 @@ func (s *DomainAccessService) GrantAccess(userID, domain string, expiresInHours
 		Domain:    domain,
 		Token:     token,
 		ExpiresAt: expiresAt,
+		Scopes:    scopeList,
 	}
 
 	if err := s.domainAccessRepo.Create(domainAccess); err != nil {
 		return nil, "", err
 	}
 
+	s.auditLog.Log(audit.ActionDomainGrant, userID, userID, ip, userAgent, map[string]any{"domain": domain})
+
 	return domainAccess, s.buildAccessURL(user.ExternalID, domain, token), nil
 }
 
-// ValidateAccess validates a domain access token
-func (s *DomainAccessService) ValidateAccess(token, domain string) (*model.User, error) {
+// ValidateAccess validates a domain access token and returns the scope set
+// it was granted, so callers can downscope what the shared link can see
+// (e.g. RequireScope-style checks before returning resource data).
+func (s *DomainAccessService) ValidateAccess(token, domain string) (*model.User, scope.Set, error) {
 	domainAccess, err := s.domainAccessRepo.FindByToken(token)
 	if err != nil {
-		return nil, ErrDomainAccessDenied
+		return nil, nil, ErrDomainAccessDenied
 	}
 
 	// Check domain matches
 	if domainAccess.Domain != domain {
-		return nil, ErrDomainAccessDenied
+		return nil, nil, ErrDomainAccessDenied
 	}
 
 	// Check expiration
 	if time.Now().After(domainAccess.ExpiresAt) {
-		return nil, ErrDomainAccessDenied
+		return nil, nil, ErrDomainAccessDenied
 	}
 
 	// Get user
-	return s.userRepo.FindByID(domainAccess.UserID)
+	user, err := s.userRepo.FindByID(domainAccess.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, scope.FromSlice(domainAccess.Scopes), nil
 }
 
 // RevokeAccess revokes a domain access token
-func (s *DomainAccessService) RevokeAccess(userID, domain string) error {
+func (s *DomainAccessService) RevokeAccess(userID, domain, userAgent, ip string) error {
 	token, err := s.domainAccessRepo.FindByUserAndDomain(userID, domain)
 	if err != nil {
 		return err
 	}
-	return s.domainAccessRepo.DeleteByID(token.ID)
+	if err := s.domainAccessRepo.DeleteByID(token.ID); err != nil {
+		return err
+	}
+	s.auditLog.Log(audit.ActionDomainRevoke, userID, userID, ip, userAgent, map[string]any{"domain": domain})
+	return nil
 }
 
 // GetUserTokens gets all active domain access tokens for a user
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/audit"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/cache"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/config"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/repository"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	mfaIssuer         = "my-girok"
+	recoveryCodeCount = 10
+	mfaMaxAttempts    = 5
+	mfaAttemptLockout = 15 * time.Minute
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("mfa already enabled")
+	ErrMFANotEnrolled    = errors.New("mfa not enrolled")
+	ErrMFALocked         = errors.New("too many failed mfa attempts")
+)
+
+// MFAService handles TOTP enrollment/verification and recovery codes.
+// Verification attempts are rate-limited per-user, backed by the same
+// cache.Cache that fronts session/domain-access token lookups (see
+// cached_session_repository.go) and AuthService.loginAttemptCache, so the
+// lockout holds across replicas instead of being bypassable by spreading
+// attempts across instances.
+type MFAService struct {
+	cfg      *config.Config
+	userRepo repository.UserStore
+	auditLog *audit.Logger
+	attempts cache.Cache
+}
+
+// attemptKey namespaces MFAService's lockout counter within the shared
+// cache, distinct from AuthService's "login_attempts:" keys.
+func attemptKey(userID string) string {
+	return "mfa_attempts:" + userID
+}
+
+// lockKey namespaces the lockout deadline set once attemptKey's counter
+// crosses mfaMaxAttempts. Kept separate from attemptKey so the counter can
+// be a plain cache.Cache.Increment integer rather than a JSON blob.
+func lockKey(userID string) string {
+	return "mfa_locked:" + userID
+}
+
+func NewMFAService(cfg *config.Config, userRepo repository.UserStore, auditLog *audit.Logger, attempts cache.Cache) *MFAService {
+	return &MFAService{
+		cfg:      cfg,
+		userRepo: userRepo,
+		auditLog: auditLog,
+		attempts: attempts,
+	}
+}
+
+// EnrollTOTP generates a new TOTP secret for the user and stores it
+// unconfirmed (TOTPEnabled stays false until ConfirmTOTP succeeds).
+func (s *MFAService) EnrollTOTP(userID string) (*model.MFAEnrollResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTPSecret = secret
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return &model.MFAEnrollResponse{
+		Secret:     secret,
+		OTPAuthURL: utils.TOTPAuthURL(mfaIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP validates the first code from the authenticator app, enables
+// MFA, and issues one-time recovery codes (returned once, stored hashed).
+func (s *MFAService) ConfirmTOTP(userID, code, userAgent, ip string) (*model.MFAConfirmResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrMFANotEnrolled
+	}
+	if !utils.ValidateTOTPCode(user.TOTPSecret, code) {
+		return nil, ErrInvalidCredentials
+	}
+
+	recoveryCodes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	hashed := make([]string, len(recoveryCodes))
+	for i, rc := range recoveryCodes {
+		h, err := bcrypt.GenerateFromPassword([]byte(rc), bcryptCost)
+		if err != nil {
+			return nil, err
+		}
+		hashed[i] = string(h)
+	}
+	stored, err := json.Marshal(hashed)
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTPEnabled = true
+	user.RecoveryCodes = string(stored)
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	s.auditLog.Log(audit.ActionMFAEnroll, user.ID, user.ID, ip, userAgent, nil)
+
+	return &model.MFAConfirmResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// DisableTOTP turns MFA off and clears the stored secret and recovery codes.
+func (s *MFAService) DisableTOTP(userID string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.RecoveryCodes = ""
+	return s.userRepo.Update(user)
+}
+
+// VerifyCodeOrRecovery checks a 6-digit TOTP code first, falling back to a
+// single-use recovery code. Rate-limits repeated failures per user.
+func (s *MFAService) VerifyCodeOrRecovery(user *model.User, code string) (bool, error) {
+	if locked, retryAfter := s.isLocked(user.ID); locked {
+		_ = retryAfter
+		return false, ErrMFALocked
+	}
+
+	if utils.ValidateTOTPCode(user.TOTPSecret, code) {
+		s.resetAttempts(user.ID)
+		return true, nil
+	}
+
+	ok, err := s.consumeRecoveryCode(user, code)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		s.resetAttempts(user.ID)
+		return true, nil
+	}
+
+	s.recordFailure(user.ID)
+	return false, nil
+}
+
+func (s *MFAService) consumeRecoveryCode(user *model.User, code string) (bool, error) {
+	if user.RecoveryCodes == "" || code == "" {
+		return false, nil
+	}
+
+	var hashed []string
+	if err := json.Unmarshal([]byte(user.RecoveryCodes), &hashed); err != nil {
+		return false, err
+	}
+
+	for i, h := range hashed {
+		if bcrypt.CompareHashAndPassword([]byte(h), []byte(code)) == nil {
+			// Single-use: remove the matched hash and persist.
+			hashed = append(hashed[:i], hashed[i+1:]...)
+			remaining, err := json.Marshal(hashed)
+			if err != nil {
+				return false, err
+			}
+			user.RecoveryCodes = string(remaining)
+			if err := s.userRepo.Update(user); err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (s *MFAService) isLocked(userID string) (bool, time.Duration) {
+	cached, found, err := s.attempts.Get(context.Background(), lockKey(userID))
+	if err != nil || !found {
+		return false, 0
+	}
+	lockedUntil, err := time.Parse(time.RFC3339Nano, cached)
+	if err != nil || time.Now().After(lockedUntil) {
+		return false, 0
+	}
+	return true, time.Until(lockedUntil)
+}
+
+// recordFailure atomically increments userID's failure count and, the
+// moment it reaches mfaMaxAttempts, sets the lockout deadline. Using
+// Increment rather than a Get-then-Set round trip means two concurrent
+// failed attempts can't both read the same pre-increment count and both
+// walk away thinking they're one failure short of a lockout.
+func (s *MFAService) recordFailure(userID string) {
+	ctx := context.Background()
+	n, err := s.attempts.Increment(ctx, attemptKey(userID), mfaAttemptLockout)
+	if err != nil || n < mfaMaxAttempts {
+		return
+	}
+	lockedUntil := time.Now().Add(mfaAttemptLockout)
+	s.attempts.Set(ctx, lockKey(userID), lockedUntil.Format(time.RFC3339Nano), mfaAttemptLockout)
+	s.attempts.Delete(ctx, attemptKey(userID))
+}
+
+func (s *MFAService) resetAttempts(userID string) {
+	ctx := context.Background()
+	s.attempts.Delete(ctx, attemptKey(userID))
+	s.attempts.Delete(ctx, lockKey(userID))
+}
@@ -4,221 +4,229 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"net/url"
-	"strings"
+	"sync"
+	"time"
 
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/config"
 	"github.com/beegy-labs/my-girok/services/auth-service/internal/model"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/oauthupstream"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/repository"
+	"github.com/beegy-labs/my-girok/services/auth-service/internal/utils"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 )
 
+// oauthStateTTL bounds how long a user has to complete the redirect dance
+// at the upstream IdP before their state record expires.
+const oauthStateTTL = 10 * time.Minute
+
+// exchangeCodeTTL bounds how long the one-time code from an OAuth callback
+// redirect stays valid; the frontend is expected to redeem it within the
+// same page load.
+const exchangeCodeTTL = 30 * time.Second
+
+// cachedProvider pairs a discovered oauthupstream.Provider with the
+// AuthProviderConfig.UpdatedAt it was built from, so config edits (a new
+// client secret, a rotated IssuerURL) invalidate the cache without a
+// restart.
+type cachedProvider struct {
+	provider  *oauthupstream.Provider
+	updatedAt time.Time
+}
+
+// OAuthService drives login against upstream OIDC/OAuth2 identity providers.
+// Providers are no longer hardcoded per vendor: each is configured by an
+// model.AuthProviderConfig row and resolved into an oauthupstream.Provider
+// on first use via discovery, so enabling Apple, GitHub, or an in-house
+// Keycloak is a config change rather than a new handler.
 type OAuthService struct {
-	cfg         *config.Config
-	authService *AuthService
+	cfg              *config.Config
+	authService      *AuthService
+	oauthConfigRepo  *repository.AuthProviderConfigRepository
+	stateRepo        *repository.OAuthStateRepository
+	exchangeCodeRepo *repository.OAuthExchangeCodeRepository
+
+	mu        sync.RWMutex
+	providers map[model.AuthProvider]cachedProvider
 }
 
-func NewOAuthService(cfg *config.Config, authService *AuthService) *OAuthService {
+func NewOAuthService(cfg *config.Config, authService *AuthService, oauthConfigRepo *repository.AuthProviderConfigRepository, stateRepo *repository.OAuthStateRepository, exchangeCodeRepo *repository.OAuthExchangeCodeRepository) *OAuthService {
 	return &OAuthService{
-		cfg:         cfg,
-		authService: authService,
+		cfg:              cfg,
+		authService:      authService,
+		oauthConfigRepo:  oauthConfigRepo,
+		stateRepo:        stateRepo,
+		exchangeCodeRepo: exchangeCodeRepo,
+		providers:        make(map[model.AuthProvider]cachedProvider),
 	}
 }
 
-// Google OAuth
-func (s *OAuthService) GetGoogleAuthURL(state string) string {
-	cfg := &oauth2.Config{
-		ClientID:     s.cfg.OAuth.Google.ClientID,
-		ClientSecret: s.cfg.OAuth.Google.ClientSecret,
-		RedirectURL:  s.cfg.OAuth.Google.RedirectURL,
-		Scopes:       []string{"openid", "email", "profile"},
-		Endpoint:     google.Endpoint,
+// MintExchangeCode stores accessToken/refreshToken under a fresh one-time
+// code for the OAuth callback redirect to hand to the browser instead of
+// the tokens themselves; see model.OAuthExchangeCode.
+func (s *OAuthService) MintExchangeCode(accessToken, refreshToken string) (string, error) {
+	code, err := utils.GenerateRandomBase62(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate exchange code: %w", err)
 	}
-	return cfg.AuthCodeURL(state, oauth2.AccessTypeOffline)
-}
-
-func (s *OAuthService) HandleGoogleCallback(ctx context.Context, code string) (*model.User, error) {
-	cfg := &oauth2.Config{
-		ClientID:     s.cfg.OAuth.Google.ClientID,
-		ClientSecret: s.cfg.OAuth.Google.ClientSecret,
-		RedirectURL:  s.cfg.OAuth.Google.RedirectURL,
-		Scopes:       []string{"openid", "email", "profile"},
-		Endpoint:     google.Endpoint,
+	if err := s.exchangeCodeRepo.Create(&model.OAuthExchangeCode{
+		Code:         code,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    time.Now().Add(exchangeCodeTTL),
+	}); err != nil {
+		return "", fmt.Errorf("failed to persist exchange code: %w", err)
 	}
+	return code, nil
+}
 
-	token, err := cfg.Exchange(ctx, code)
+// ConsumeExchangeCode redeems a one-time code minted by MintExchangeCode.
+func (s *OAuthService) ConsumeExchangeCode(code string) (accessToken, refreshToken string, err error) {
+	record, err := s.exchangeCodeRepo.Consume(code)
 	if err != nil {
-		return nil, fmt.Errorf("failed to exchange code: %w", err)
+		return "", "", err
 	}
+	return record.AccessToken, record.RefreshToken, nil
+}
 
-	client := cfg.Client(ctx, token)
-	resp, err := client.Get("https://www.googleapis.com/oauth2/v2/userinfo")
+// GetAuthURL resolves provider, records a server-side state record, and
+// returns the upstream authorization URL to redirect the user to.
+func (s *OAuthService) GetAuthURL(ctx context.Context, provider model.AuthProvider) (string, error) {
+	p, err := s.resolveProvider(ctx, provider)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %w", err)
-	}
-	defer resp.Body.Close()
-
-	var userInfo struct {
-		ID      string `json:"id"`
-		Email   string `json:"email"`
-		Name    string `json:"name"`
-		Picture string `json:"picture"`
+		return "", err
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
-		return nil, fmt.Errorf("failed to decode user info: %w", err)
-	}
-
-	return s.authService.FindOrCreateOAuthUser(
-		model.ProviderGoogle,
-		userInfo.ID, // Google's user ID as providerID
-		userInfo.Email,
-		userInfo.Name,
-		userInfo.Picture,
-	)
-}
-
-// Kakao OAuth
-func (s *OAuthService) GetKakaoAuthURL(state string) string {
-	params := url.Values{
-		"client_id":     {s.cfg.OAuth.Kakao.ClientID},
-		"redirect_uri":  {s.cfg.OAuth.Kakao.RedirectURL},
-		"response_type": {"code"},
-		"state":         {state},
+	state, err := utils.GenerateRandomBase62(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate state: %w", err)
 	}
-	return "https://kauth.kakao.com/oauth/authorize?" + params.Encode()
+	verifier, err := oauthupstream.NewPKCEVerifier()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate pkce verifier: %w", err)
+	}
+	if err := s.stateRepo.Create(&model.OAuthState{
+		Provider:     provider,
+		State:        state,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oauthStateTTL),
+	}); err != nil {
+		return "", fmt.Errorf("failed to persist oauth state: %w", err)
+	}
+
+	oauth2Cfg := p.OAuth2Config()
+	return oauth2Cfg.AuthCodeURL(state, oauth2.AccessTypeOffline,
+		oauth2.SetAuthURLParam("code_challenge", oauthupstream.S256Challenge(verifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	), nil
 }
 
-func (s *OAuthService) HandleKakaoCallback(ctx context.Context, code string) (*model.User, error) {
-	// Exchange code for token
-	tokenURL := "https://kauth.kakao.com/oauth/token"
-	data := url.Values{
-		"grant_type":   {"authorization_code"},
-		"client_id":    {s.cfg.OAuth.Kakao.ClientID},
-		"redirect_uri": {s.cfg.OAuth.Kakao.RedirectURL},
-		"code":         {code},
+// HandleCallback consumes the server-side state, exchanges code for a
+// token, fetches userinfo, and maps it through the provider's
+// UserInfoMapping (falling back to oauthupstream's default aliases) to
+// find-or-create the local user.
+func (s *OAuthService) HandleCallback(ctx context.Context, provider model.AuthProvider, code, state, userAgent, ip string) (*model.User, error) {
+	oauthState, err := s.stateRepo.Consume(provider, state)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired oauth state: %w", err)
 	}
 
-	if s.cfg.OAuth.Kakao.ClientSecret != "" {
-		data.Set("client_secret", s.cfg.OAuth.Kakao.ClientSecret)
+	p, err := s.resolveProvider(ctx, provider)
+	if err != nil {
+		return nil, err
 	}
 
-	resp, err := http.Post(tokenURL, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
+	token, err := p.OAuth2Config().Exchange(ctx, code,
+		oauth2.SetAuthURLParam("code_verifier", oauthState.CodeVerifier),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code: %w", err)
 	}
-	defer resp.Body.Close()
 
-	var tokenResp struct {
-		AccessToken string `json:"access_token"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return nil, fmt.Errorf("failed to decode token: %w", err)
+	client := p.OAuth2Config().Client(ctx, token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.UserInfoEndpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build userinfo request: %w", err)
 	}
-
-	// Get user info
-	req, _ := http.NewRequest("GET", "https://kapi.kakao.com/v2/user/me", nil)
-	req.Header.Set("Authorization", "Bearer "+tokenResp.AccessToken)
-
-	client := &http.Client{}
-	userResp, err := client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
-	defer userResp.Body.Close()
+	defer resp.Body.Close()
 
-	var userInfo struct {
-		ID           int64 `json:"id"`
-		KakaoAccount struct {
-			Email   string `json:"email"`
-			Profile struct {
-				Nickname string `json:"nickname"`
-				Image    string `json:"profile_image_url"`
-			} `json:"profile"`
-		} `json:"kakao_account"`
+	var fields oauthupstream.UserInfoFields
+	if err := json.NewDecoder(resp.Body).Decode(&fields); err != nil {
+		return nil, fmt.Errorf("failed to decode user info: %w", err)
 	}
 
-	if err := json.NewDecoder(userResp.Body).Decode(&userInfo); err != nil {
-		return nil, fmt.Errorf("failed to decode user info: %w", err)
+	providerID := fields.Resolve("id", p.UserInfoMapping)
+	if providerID == "" {
+		return nil, fmt.Errorf("userinfo response missing subject identifier")
 	}
 
+	// RoleMapping lets an IdP's groups/roles claim assign a local role
+	// (e.g. an "girok-admins" group granting MASTER); unmatched groups
+	// resolve to "" and FindOrCreateOAuthUser leaves the role untouched.
+	role := oauthupstream.ResolveRole(fields.ResolveGroups(p.UserInfoMapping), p.RoleMapping)
+
 	return s.authService.FindOrCreateOAuthUser(
-		model.ProviderKakao,
-		fmt.Sprintf("%d", userInfo.ID),
-		userInfo.KakaoAccount.Email,
-		userInfo.KakaoAccount.Profile.Nickname,
-		userInfo.KakaoAccount.Profile.Image,
+		provider,
+		providerID,
+		fields.Resolve("email", p.UserInfoMapping),
+		fields.Resolve("name", p.UserInfoMapping),
+		fields.Resolve("picture", p.UserInfoMapping),
+		role,
+		fields.EmailVerified(),
+		userAgent,
+		ip,
 	)
 }
 
-// Naver OAuth
-func (s *OAuthService) GetNaverAuthURL(state string) string {
-	params := url.Values{
-		"client_id":     {s.cfg.OAuth.Naver.ClientID},
-		"redirect_uri":  {s.cfg.OAuth.Naver.RedirectURL},
-		"response_type": {"code"},
-		"state":         {state},
-	}
-	return "https://nid.naver.com/oauth2.0/authorize?" + params.Encode()
-}
-
-func (s *OAuthService) HandleNaverCallback(ctx context.Context, code, state string) (*model.User, error) {
-	// Exchange code for token
-	tokenURL := "https://nid.naver.com/oauth2.0/token"
-	params := url.Values{
-		"grant_type":    {"authorization_code"},
-		"client_id":     {s.cfg.OAuth.Naver.ClientID},
-		"client_secret": {s.cfg.OAuth.Naver.ClientSecret},
-		"code":          {code},
-		"state":         {state},
-	}
-
-	resp, err := http.Get(tokenURL + "?" + params.Encode())
+// resolveProvider returns the cached oauthupstream.Provider for provider,
+// rebuilding (and re-running discovery) whenever the DB row has changed
+// since it was last cached.
+func (s *OAuthService) resolveProvider(ctx context.Context, provider model.AuthProvider) (*oauthupstream.Provider, error) {
+	dbConfig, err := s.oauthConfigRepo.FindByProvider(provider)
 	if err != nil {
-		return nil, fmt.Errorf("failed to exchange code: %w", err)
+		return nil, fmt.Errorf("unknown oauth provider %q: %w", provider, err)
 	}
-	defer resp.Body.Close()
-
-	var tokenResp struct {
-		AccessToken string `json:"access_token"`
+	if !dbConfig.Enabled {
+		return nil, fmt.Errorf("oauth provider %q is disabled", provider)
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return nil, fmt.Errorf("failed to decode token: %w", err)
+	if dbConfig.IssuerURL == "" && !oauthupstream.HasStaticEndpoints(string(provider)) {
+		return nil, fmt.Errorf("oauth provider %q has no issuer URL configured", provider)
 	}
 
-	// Get user info
-	req, _ := http.NewRequest("GET", "https://openapi.naver.com/v1/nid/me", nil)
-	req.Header.Set("Authorization", "Bearer "+tokenResp.AccessToken)
+	s.mu.RLock()
+	cached, ok := s.providers[provider]
+	s.mu.RUnlock()
+	if ok && cached.updatedAt.Equal(dbConfig.UpdatedAt) {
+		return cached.provider, nil
+	}
 
-	client := &http.Client{}
-	userResp, err := client.Do(req)
+	doc, err := oauthupstream.DiscoverOrStatic(ctx, string(provider), dbConfig.IssuerURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %w", err)
+		return nil, fmt.Errorf("failed to discover oauth provider %q: %w", provider, err)
 	}
-	defer userResp.Body.Close()
 
-	body, _ := io.ReadAll(userResp.Body)
-
-	var userInfo struct {
-		Response struct {
-			ID      string `json:"id"`
-			Email   string `json:"email"`
-			Name    string `json:"name"`
-			Picture string `json:"profile_image"`
-		} `json:"response"`
+	p := &oauthupstream.Provider{
+		Name:                  string(provider),
+		ClientID:              dbConfig.ClientID,
+		ClientSecret:          dbConfig.ClientSecret,
+		RedirectURL:           dbConfig.CallbackURL,
+		Scopes:                oauthupstream.SplitScopes(dbConfig.Scopes),
+		UserInfoMapping:       oauthupstream.ParseUserInfoMapping(dbConfig.UserInfoMapping),
+		RoleMapping:           oauthupstream.ParseRoleMapping(dbConfig.RoleMapping),
+		AuthorizationEndpoint: doc.AuthorizationEndpoint,
+		TokenEndpoint:         doc.TokenEndpoint,
+		UserInfoEndpoint:      doc.UserInfoEndpoint,
+		JWKSURI:               doc.JWKSURI,
 	}
 
-	if err := json.Unmarshal(body, &userInfo); err != nil {
-		return nil, fmt.Errorf("failed to decode user info: %w", err)
-	}
+	s.mu.Lock()
+	s.providers[provider] = cachedProvider{provider: p, updatedAt: dbConfig.UpdatedAt}
+	s.mu.Unlock()
 
-	return s.authService.FindOrCreateOAuthUser(
-		model.ProviderNaver,
-		userInfo.Response.ID,
-		userInfo.Response.Email,
-		userInfo.Response.Name,
-		userInfo.Response.Picture,
-	)
+	return p, nil
 }
+
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// ExternalID is a utils-minted ID (either GenerateExternalID's or
+// GenerateMonotonicExternalID's format) that knows how to scan out of a
+// database column, write back as a driver.Value, and marshal to/from JSON
+// as a bare string - so a model field or DTO can use this type directly
+// instead of a plain string plus a manual IsValidExternalID check at every
+// boundary. The zero value is the empty string, treated as SQL NULL / JSON
+// null rather than a validation failure.
+type ExternalID string
+
+// Scan implements sql.Scanner. Accepts string, []byte, and nil; any other
+// source type, or a non-nil value failing IsValidExternalID, is rejected.
+func (id *ExternalID) Scan(src any) error {
+	if src == nil {
+		*id = ""
+		return nil
+	}
+
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("utils: cannot scan %T into ExternalID", src)
+	}
+
+	if !IsValidExternalID(s) {
+		return fmt.Errorf("utils: invalid external ID %q", s)
+	}
+	*id = ExternalID(s)
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (id ExternalID) Value() (driver.Value, error) {
+	if id == "" {
+		return nil, nil
+	}
+	if !IsValidExternalID(string(id)) {
+		return nil, fmt.Errorf("utils: invalid external ID %q", string(id))
+	}
+	return string(id), nil
+}
+
+// MarshalJSON emits the bare ID string, or JSON null for the zero value -
+// not the quoted byte array a raw []byte-backed type would produce.
+func (id ExternalID) MarshalJSON() ([]byte, error) {
+	if id == "" {
+		return []byte("null"), nil
+	}
+	return json.Marshal(string(id))
+}
+
+// UnmarshalJSON accepts a JSON string or null.
+func (id *ExternalID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*id = ""
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if !IsValidExternalID(s) {
+		return fmt.Errorf("utils: invalid external ID %q", s)
+	}
+	*id = ExternalID(s)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, for callers that round-trip
+// through text rather than JSON (query params, env vars, map keys).
+func (id ExternalID) MarshalText() ([]byte, error) {
+	return []byte(id), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (id *ExternalID) UnmarshalText(text []byte) error {
+	s := string(text)
+	if s == "" {
+		*id = ""
+		return nil
+	}
+	if !IsValidExternalID(s) {
+		return fmt.Errorf("utils: invalid external ID %q", s)
+	}
+	*id = ExternalID(s)
+	return nil
+}
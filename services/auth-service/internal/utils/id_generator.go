@@ -3,6 +3,7 @@ package utils
 import (
 	"crypto/rand"
 	"errors"
+	"sync"
 	"time"
 )
 
@@ -16,6 +17,11 @@ var epochMS = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
 // Maximum retry attempts for collision avoidance
 const maxRetryAttempts = 3
 
+// externalIDLength is the length of an ID minted by GenerateExternalID:
+// [8-char timestamp][2-char random], with no version marker since it
+// predates ExternalIDVersion.
+const externalIDLength = 10
+
 // Base62Encode encodes a number to Base62 string
 func Base62Encode(num int64) string {
 	if num == 0 {
@@ -137,31 +143,22 @@ func GenerateUniqueExternalID(checkUniqueness CheckUniqueness) (string, error) {
 	return "", errors.New("failed to generate unique external ID after maximum attempts")
 }
 
-// ExtractTimestampFromExternalID extracts creation timestamp from external ID
-func ExtractTimestampFromExternalID(externalID string) (time.Time, error) {
-	if len(externalID) != 10 {
-		return time.Time{}, errors.New("invalid external ID format")
-	}
-
-	timePart := externalID[:8]
+// decodeTimePart decodes an 8-char Base62 timestamp segment (shared by
+// both ExternalID formats) into the wall-clock time it encodes.
+func decodeTimePart(timePart string) (time.Time, error) {
 	timeSinceEpoch, err := Base62Decode(timePart)
 	if err != nil {
 		return time.Time{}, err
 	}
-
 	return time.UnixMilli(epochMS + timeSinceEpoch), nil
 }
 
-// IsValidExternalID validates external ID format
-func IsValidExternalID(externalID string) bool {
-	if len(externalID) != 10 {
-		return false
-	}
-
-	for i := 0; i < len(externalID); i++ {
+// isBase62 reports whether every character of s is in base62Chars.
+func isBase62(s string) bool {
+	for i := 0; i < len(s); i++ {
 		valid := false
 		for j := 0; j < len(base62Chars); j++ {
-			if base62Chars[j] == externalID[i] {
+			if base62Chars[j] == s[i] {
 				valid = true
 				break
 			}
@@ -170,6 +167,191 @@ func IsValidExternalID(externalID string) bool {
 			return false
 		}
 	}
+	return true
+}
+
+// ExtractTimestampFromExternalID extracts creation timestamp from external
+// ID, dispatching on its length and (for the monotonic format)
+// ExternalIDVersion byte - both GenerateExternalID's legacy 10-char IDs and
+// GenerateMonotonicExternalID's IDs encode the same 8-char Base62 timestamp,
+// just at different offsets.
+func ExtractTimestampFromExternalID(externalID string) (time.Time, error) {
+	switch len(externalID) {
+	case externalIDLength:
+		return decodeTimePart(externalID[:8])
+	case monotonicExternalIDLength:
+		if decodeExternalIDVersion(externalID[0]) != ExternalIDVersionMonotonic {
+			return time.Time{}, errors.New("invalid external ID format")
+		}
+		return decodeTimePart(externalID[1:9])
+	default:
+		return time.Time{}, errors.New("invalid external ID format")
+	}
+}
+
+// IsValidExternalID validates external ID format, accepting either
+// GenerateExternalID's legacy format or GenerateMonotonicExternalID's.
+func IsValidExternalID(externalID string) bool {
+	switch len(externalID) {
+	case externalIDLength:
+		return isBase62(externalID)
+	case monotonicExternalIDLength:
+		return decodeExternalIDVersion(externalID[0]) == ExternalIDVersionMonotonic && isBase62(externalID[1:])
+	default:
+		return false
+	}
+}
+
+// ExternalIDVersion identifies which of utils' generation schemes produced
+// an external ID, encoded as the Base62 character at id[0]. Legacy IDs from
+// GenerateExternalID predate this byte entirely and are recognized by their
+// fixed 10-char length instead (ExternalIDVersionLegacy is never actually
+// read off the wire).
+type ExternalIDVersion byte
+
+const (
+	ExternalIDVersionLegacy    ExternalIDVersion = 0
+	ExternalIDVersionMonotonic ExternalIDVersion = 1
+)
+
+// decodeExternalIDVersion maps the first character of a non-legacy
+// external ID back to the ExternalIDVersion it encodes. Returns a value
+// that matches no declared constant if c isn't a Base62 character.
+func decodeExternalIDVersion(c byte) ExternalIDVersion {
+	for i := 0; i < len(base62Chars); i++ {
+		if base62Chars[i] == c {
+			return ExternalIDVersion(i)
+		}
+	}
+	return ExternalIDVersion(255)
+}
+
+// monotonicRandomLen is the width of GenerateMonotonicExternalID's
+// random/counter suffix: 6 Base62 characters (~35.7 bits), wide enough that
+// overflowing it within a single millisecond (see monotonicState.next) is
+// astronomically unlikely.
+const monotonicRandomLen = 6
+
+// monotonicExternalIDLength is [1-char version][8-char timestamp][6-char
+// random/counter suffix].
+const monotonicExternalIDLength = 1 + 8 + monotonicRandomLen
+
+// monotonicState is the package-level state behind
+// GenerateMonotonicExternalID, guarded by mu. lastRnd holds Base62 digit
+// indices (0-61), not characters, so bumping the suffix within a
+// millisecond is a plain big-endian increment-with-carry.
+type monotonicState struct {
+	mu      sync.Mutex
+	lastMS  int64
+	lastRnd [monotonicRandomLen]byte
+}
+
+var monotonicGen monotonicState
+
+// next returns the millisecond and random/counter suffix for the next
+// monotonic ID, following the UUIDv7/ULID-monotonic approach: a fresh
+// random suffix when the clock has advanced past the last ID minted, or
+// the previous suffix incremented by one when it hasn't (including the
+// clock appearing to go backwards), so IDs minted within the same
+// millisecond still sort in generation order.
+func (g *monotonicState) next() (int64, [monotonicRandomLen]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	if now > g.lastMS {
+		rnd, err := randomDigits(monotonicRandomLen)
+		if err != nil {
+			return 0, [monotonicRandomLen]byte{}, err
+		}
+		g.lastMS = now
+		g.lastRnd = rnd
+		return g.lastMS, g.lastRnd, nil
+	}
 
+	if overflowed := incrementDigits(&g.lastRnd); overflowed {
+		// The suffix wrapped all the way around within one millisecond:
+		// move to the next millisecond and start over rather than reuse it.
+		rnd, err := randomDigits(monotonicRandomLen)
+		if err != nil {
+			return 0, [monotonicRandomLen]byte{}, err
+		}
+		g.lastMS++
+		g.lastRnd = rnd
+	}
+	return g.lastMS, g.lastRnd, nil
+}
+
+// randomDigits draws n fresh Base62 digit indices (0-61) from crypto/rand.
+func randomDigits(n int) ([monotonicRandomLen]byte, error) {
+	var digits [monotonicRandomLen]byte
+	raw := make([]byte, n)
+	if _, err := rand.Read(raw); err != nil {
+		return digits, err
+	}
+	for i, b := range raw {
+		digits[i] = b % 62
+	}
+	return digits, nil
+}
+
+// incrementDigits adds one to digits, treated as a big-endian base-62
+// integer, and reports whether doing so overflowed (every digit wrapped
+// back to 0).
+func incrementDigits(digits *[monotonicRandomLen]byte) (overflowed bool) {
+	for i := len(digits) - 1; i >= 0; i-- {
+		digits[i]++
+		if digits[i] < 62 {
+			return false
+		}
+		digits[i] = 0
+	}
 	return true
 }
+
+// GenerateMonotonicExternalID generates a k-sortable external ID following
+// the UUIDv7/ULID-monotonic approach: unlike GenerateExternalID, IDs minted
+// within the same millisecond still sort in generation order because the
+// random suffix is incremented rather than re-randomized (see
+// monotonicState.next). Format: [1-char ExternalIDVersionMonotonic][8-char
+// Base62 timestamp][6-char Base62 counter/random suffix] = 15 characters.
+func GenerateMonotonicExternalID() (string, error) {
+	ms, rnd, err := monotonicGen.next()
+	if err != nil {
+		return "", err
+	}
+
+	timePart := padLeft(Base62Encode(ms-epochMS), '0', 8)
+	randPart := make([]byte, monotonicRandomLen)
+	for i, idx := range rnd {
+		randPart[i] = base62Chars[idx]
+	}
+
+	return string(base62Chars[ExternalIDVersionMonotonic]) + timePart + string(randPart), nil
+}
+
+// GenerateUniqueMonotonicExternalID generates a GenerateMonotonicExternalID
+// with collision checking, mirroring GenerateUniqueExternalID. A collision
+// here would mean the same millisecond's counter wrapped back onto a value
+// already persisted, which monotonicRandomLen is sized to make effectively
+// impossible; the retry loop exists for parity with the legacy generator,
+// not because collisions are expected.
+func GenerateUniqueMonotonicExternalID(checkUniqueness CheckUniqueness) (string, error) {
+	for attempt := 1; attempt <= maxRetryAttempts; attempt++ {
+		externalID, err := GenerateMonotonicExternalID()
+		if err != nil {
+			return "", err
+		}
+
+		isUnique, err := checkUniqueness(externalID)
+		if err != nil {
+			return "", err
+		}
+
+		if isUnique {
+			return externalID, nil
+		}
+	}
+
+	return "", errors.New("failed to generate unique external ID after maximum attempts")
+}
@@ -0,0 +1,29 @@
+package utils
+
+import "strings"
+
+// recoveryCodeGroups/recoveryCodeGroupLen control the human-friendly
+// "XXXX-XXXX" shape of generated recovery codes.
+const (
+	recoveryCodeGroups   = 2
+	recoveryCodeGroupLen = 4
+)
+
+// GenerateRecoveryCodes generates n one-time MFA recovery codes in
+// "XXXX-XXXX" Base62 form. Callers are expected to persist only a hash
+// of each code (see bcrypt usage in service.MFAService).
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		groups := make([]string, recoveryCodeGroups)
+		for g := 0; g < recoveryCodeGroups; g++ {
+			part, err := GenerateRandomBase62(recoveryCodeGroupLen)
+			if err != nil {
+				return nil, err
+			}
+			groups[g] = part
+		}
+		codes = append(codes, strings.Join(groups, "-"))
+	}
+	return codes, nil
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"time"
+)
+
+// signedExternalIDSigLen is the width of the HMAC-SHA256 signature suffix
+// appended to a signed external ID: 6 Base62 characters (~35.7 bits),
+// enough to make offline forgery or enumeration impractical without
+// growing the ID much past GenerateExternalID's 10 characters.
+const signedExternalIDSigLen = 6
+
+// GenerateSignedExternalID generates a fresh GenerateExternalID and appends
+// an HMAC-SHA256 signature over it, truncated to signedExternalIDSigLen
+// Base62 characters, so the result can be handed to a client (in a URL, for
+// instance) without letting them forge or enumerate adjacent IDs.
+func GenerateSignedExternalID(secret []byte) (string, error) {
+	id, err := GenerateExternalID()
+	if err != nil {
+		return "", err
+	}
+	return id + signExternalID(id, secret), nil
+}
+
+// signExternalID computes the Base62 signature suffix for id under key,
+// one Base62 character per signature byte - the same byte-to-char mapping
+// GenerateRandomBase62 uses, just driven by an HMAC instead of crypto/rand.
+func signExternalID(id string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(id))
+	sum := mac.Sum(nil)
+
+	sig := make([]byte, signedExternalIDSigLen)
+	for i := 0; i < signedExternalIDSigLen; i++ {
+		sig[i] = base62Chars[int(sum[i])%62]
+	}
+	return string(sig)
+}
+
+// VerifyAndParseSignedExternalID verifies id's HMAC-SHA256 signature under
+// secret (see GenerateSignedExternalID) and, once verified, returns the
+// creation timestamp encoded in its ExternalID prefix.
+func VerifyAndParseSignedExternalID(id string, secret []byte) (time.Time, error) {
+	return VerifyAndParseSignedExternalIDRotated(id, [][]byte{secret})
+}
+
+// VerifyAndParseSignedExternalIDRotated is VerifyAndParseSignedExternalID
+// with key rotation support: id is accepted if it verifies under any key in
+// keys (try the current signing key first, then as many prior keys as are
+// still honored), so an operator can rotate the signing key without
+// invalidating IDs already handed out under a previous one.
+func VerifyAndParseSignedExternalIDRotated(id string, keys [][]byte) (time.Time, error) {
+	if len(id) <= signedExternalIDSigLen {
+		return time.Time{}, errors.New("invalid external ID signature")
+	}
+
+	prefix := id[:len(id)-signedExternalIDSigLen]
+	sig := []byte(id[len(id)-signedExternalIDSigLen:])
+
+	verified := false
+	for _, key := range keys {
+		// hmac.Equal compares in constant time, so a caller probing for a
+		// forged signature can't learn anything from how long verification
+		// against each candidate key took.
+		if hmac.Equal(sig, []byte(signExternalID(prefix, key))) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return time.Time{}, errors.New("invalid external ID signature")
+	}
+
+	return ExtractTimestampFromExternalID(prefix)
+}
@@ -0,0 +1,46 @@
+// Package userstorage defines the boundary between auth-service and a
+// pluggable user-identity backend. The host and any out-of-process plugin
+// (see plugins/bolt-user-storage for a reference implementation) share only
+// this package, never auth-service's internal packages, so a plugin can
+// live in its own module and be built independently of the host.
+package userstorage
+
+import "errors"
+
+// ErrNotFound is the sentinel every Store implementation (and the gRPC
+// client adapter translating codes.NotFound back) returns for a missing
+// record, so the host's repository-level error handling stays unchanged
+// regardless of which backend is active.
+var ErrNotFound = errors.New("user not found")
+
+// Record is the wire representation of a user identity. It mirrors
+// internal/model.User's externally-relevant fields; the host is responsible
+// for translating to/from its own model on each side of the plugin boundary.
+type Record struct {
+	ID            string
+	ExternalID    string
+	Email         string
+	Username      string
+	PasswordHash  string
+	Role          string
+	Provider      string
+	ProviderID    string
+	Name          string
+	Picture       string
+	EmailVerified bool
+	IsActive      bool
+}
+
+// Store is the contract every user-storage backend must implement, whether
+// built in (GORM/Postgres) or loaded as a subprocess plugin.
+type Store interface {
+	Create(rec *Record) error
+	FindByID(id string) (*Record, error)
+	FindByEmail(email string) (*Record, error)
+	FindByUsername(username string) (*Record, error)
+	FindByExternalID(externalID string) (*Record, error)
+	FindByProviderID(provider, providerID string) (*Record, error)
+	Update(rec *Record) error
+	Delete(id string) error
+	List(page, perPage int) ([]Record, int64, error)
+}
@@ -0,0 +1,283 @@
+package userstorage
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"io"
+
+	"github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+)
+
+// gobCodec lets the gRPC transport carry our plain Go structs without a
+// .proto/protoc step - it's the same wire contract, just encoded with
+// encoding/gob instead of protobuf.
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf []byte
+	w := &byteBuffer{b: &buf}
+	if err := gob.NewEncoder(w).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	r := &byteBuffer{b: &data}
+	return gob.NewDecoder(r).Decode(v)
+}
+
+func (gobCodec) Name() string { return "gob" }
+
+type byteBuffer struct {
+	b   *[]byte
+	pos int
+}
+
+func (bb *byteBuffer) Write(p []byte) (int, error) {
+	*bb.b = append(*bb.b, p...)
+	return len(p), nil
+}
+
+func (bb *byteBuffer) Read(p []byte) (int, error) {
+	if bb.pos >= len(*bb.b) {
+		return 0, io.EOF
+	}
+	n := copy(p, (*bb.b)[bb.pos:])
+	bb.pos += n
+	return n, nil
+}
+
+func init() {
+	encoding.RegisterCodec(gobCodec{})
+}
+
+const serviceName = "userstorage.UserStorage"
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*Store)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Create", Handler: createHandler},
+		{MethodName: "FindByID", Handler: findByIDHandler},
+		{MethodName: "FindByEmail", Handler: findByEmailHandler},
+		{MethodName: "FindByUsername", Handler: findByUsernameHandler},
+		{MethodName: "FindByExternalID", Handler: findByExternalIDHandler},
+		{MethodName: "FindByProviderID", Handler: findByProviderIDHandler},
+		{MethodName: "Update", Handler: updateHandler},
+		{MethodName: "Delete", Handler: deleteHandler},
+		{MethodName: "List", Handler: listHandler},
+	},
+}
+
+// Request/response envelopes for the handful of RPCs that need more than a
+// single scalar or *Record.
+type idRequest struct{ ID string }
+type emailRequest struct{ Email string }
+type usernameRequest struct{ Username string }
+type externalIDRequest struct{ ExternalID string }
+type providerIDRequest struct{ Provider, ProviderID string }
+type listRequest struct{ Page, PerPage int }
+type listResponse struct {
+	Records []Record
+	Total   int64
+}
+type empty struct{}
+
+func createHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req Record
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	return &empty{}, toStatus(srv.(Store).Create(&req))
+}
+
+func findByIDHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req idRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	rec, err := srv.(Store).FindByID(req.ID)
+	return recordOrStatus(rec, err)
+}
+
+func findByEmailHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req emailRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	rec, err := srv.(Store).FindByEmail(req.Email)
+	return recordOrStatus(rec, err)
+}
+
+func findByUsernameHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req usernameRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	rec, err := srv.(Store).FindByUsername(req.Username)
+	return recordOrStatus(rec, err)
+}
+
+func findByExternalIDHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req externalIDRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	rec, err := srv.(Store).FindByExternalID(req.ExternalID)
+	return recordOrStatus(rec, err)
+}
+
+func findByProviderIDHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req providerIDRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	rec, err := srv.(Store).FindByProviderID(req.Provider, req.ProviderID)
+	return recordOrStatus(rec, err)
+}
+
+func updateHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req Record
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	return &empty{}, toStatus(srv.(Store).Update(&req))
+}
+
+func deleteHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req idRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	return &empty{}, toStatus(srv.(Store).Delete(req.ID))
+}
+
+func listHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req listRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	records, total, err := srv.(Store).List(req.Page, req.PerPage)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &listResponse{Records: records, Total: total}, nil
+}
+
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func recordOrStatus(rec *Record, err error) (interface{}, error) {
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return rec, nil
+}
+
+func fromStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if status.Code(err) == codes.NotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+// GRPCClient is the host-side handle to a plugin process; it satisfies Store
+// by invoking the RPCs defined above over conn.
+type GRPCClient struct {
+	conn *grpc.ClientConn
+}
+
+func (c *GRPCClient) invoke(ctx context.Context, method string, in, out interface{}) error {
+	return c.conn.Invoke(ctx, "/"+serviceName+"/"+method, in, out, grpc.CallContentSubtype("gob"))
+}
+
+func (c *GRPCClient) Create(rec *Record) error {
+	return fromStatus(c.invoke(context.Background(), "Create", rec, &empty{}))
+}
+
+func (c *GRPCClient) FindByID(id string) (*Record, error) {
+	var rec Record
+	err := c.invoke(context.Background(), "FindByID", &idRequest{ID: id}, &rec)
+	return nilOnErr(&rec, fromStatus(err))
+}
+
+func (c *GRPCClient) FindByEmail(email string) (*Record, error) {
+	var rec Record
+	err := c.invoke(context.Background(), "FindByEmail", &emailRequest{Email: email}, &rec)
+	return nilOnErr(&rec, fromStatus(err))
+}
+
+func (c *GRPCClient) FindByUsername(username string) (*Record, error) {
+	var rec Record
+	err := c.invoke(context.Background(), "FindByUsername", &usernameRequest{Username: username}, &rec)
+	return nilOnErr(&rec, fromStatus(err))
+}
+
+func (c *GRPCClient) FindByExternalID(externalID string) (*Record, error) {
+	var rec Record
+	err := c.invoke(context.Background(), "FindByExternalID", &externalIDRequest{ExternalID: externalID}, &rec)
+	return nilOnErr(&rec, fromStatus(err))
+}
+
+func (c *GRPCClient) FindByProviderID(provider, providerID string) (*Record, error) {
+	var rec Record
+	err := c.invoke(context.Background(), "FindByProviderID", &providerIDRequest{Provider: provider, ProviderID: providerID}, &rec)
+	return nilOnErr(&rec, fromStatus(err))
+}
+
+func (c *GRPCClient) Update(rec *Record) error {
+	return fromStatus(c.invoke(context.Background(), "Update", rec, &empty{}))
+}
+
+func (c *GRPCClient) Delete(id string) error {
+	return fromStatus(c.invoke(context.Background(), "Delete", &idRequest{ID: id}, &empty{}))
+}
+
+func (c *GRPCClient) List(page, perPage int) ([]Record, int64, error) {
+	var resp listResponse
+	err := c.invoke(context.Background(), "List", &listRequest{Page: page, PerPage: perPage}, &resp)
+	if err != nil {
+		return nil, 0, fromStatus(err)
+	}
+	return resp.Records, resp.Total, nil
+}
+
+func nilOnErr(rec *Record, err error) (*Record, error) {
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// GRPCPlugin is the go-plugin.GRPCPlugin implementation shared by the host
+// (dispensing a GRPCClient) and every plugin binary (serving a GRPCServer
+// wrapping its own Store).
+type GRPCPlugin struct {
+	plugin.Plugin
+	Impl Store
+}
+
+func (p *GRPCPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	s.RegisterService(&serviceDesc, p.Impl)
+	return nil
+}
+
+func (p *GRPCPlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return &GRPCClient{conn: c}, nil
+}
@@ -0,0 +1,17 @@
+package userstorage
+
+import "github.com/hashicorp/go-plugin"
+
+// Handshake is shared verbatim by the host (plugin.ClientConfig) and every
+// plugin binary (plugin.Serve) so a stray non-plugin subprocess can't be
+// mistaken for one.
+var Handshake = plugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "MY_GIROK_USER_STORAGE_PLUGIN",
+	MagicCookieValue: "girok",
+}
+
+// PluginMap is keyed by the single plugin name this package serves.
+var PluginMap = map[string]plugin.Plugin{
+	"user_storage": &GRPCPlugin{},
+}